@@ -25,18 +25,25 @@ Aliases allow you to create shortcuts for base URLs, so you can use
 		Short: "List all aliases",
 		Run:   runAliasList,
 	}
+	addOutputFlag(listCmd)
 
 	createCmd := &cobra.Command{
 		Use:   "create <name> <url>",
 		Short: "Create a new alias",
 		Long: `Create a new alias for a base URL.
 
+Default query parameters (-q) are added to every request that uses the alias,
+unless the request sets the same parameter itself. Creating an alias that
+already exists replaces its URL and default parameters.
+
 Example:
   apicli alias create starwars https://www.swapi.tech/api
-  apicli get starwars/people/1`,
+  apicli get starwars/people/1
+  apicli alias create staging https://staging.example.com/api -q api_version=2 -q key={{env:STAGING_KEY}}`,
 		Args: cobra.ExactArgs(2),
 		Run:  runAliasCreate,
 	}
+	createCmd.Flags().StringArrayVarP(&queryParams, "query", "q", []string{}, "Default query parameter key=value (can be used multiple times)")
 
 	showCmd := &cobra.Command{
 		Use:   "show <name>",
@@ -69,6 +76,13 @@ func runAliasList(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
+	if out := outputFromFlags(cmd); !out.IsDefault() {
+		if err := out.Aliases(aliases); err != nil {
+			format.PrintError(err.Error())
+			os.Exit(1)
+		}
+		return
+	}
 	format.PrintAliasList(aliases)
 }
 
@@ -82,11 +96,22 @@ func runAliasCreate(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
+	query, err := parseQueryFlags(queryParams)
+	if err != nil {
+		format.PrintError(err.Error())
+		os.Exit(1)
+	}
+
 	if err := store.CreateAlias(name, url); err != nil {
 		format.PrintError(fmt.Sprintf("Failed to create alias: %v", err))
 		os.Exit(1)
 	}
 
+	if err := store.SetAliasQuery(name, query); err != nil {
+		format.PrintError(fmt.Sprintf("Failed to create alias: %v", err))
+		os.Exit(1)
+	}
+
 	format.PrintSuccess(fmt.Sprintf("Alias '%s' created for %s", name, url))
 }
 
@@ -110,7 +135,14 @@ func runAliasShow(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
+	query, err := store.GetAliasQuery(name)
+	if err != nil {
+		format.PrintError(fmt.Sprintf("Failed to load alias: %v", err))
+		os.Exit(1)
+	}
+
 	format.PrintAlias(name, url)
+	format.PrintQueryParams(query, "  ")
 }
 
 func runAliasDelete(cmd *cobra.Command, args []string) {
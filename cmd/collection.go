@@ -23,6 +23,7 @@ func init() {
 		Short: "List all collections",
 		Run:   runCollectionList,
 	}
+	addOutputFlag(listCmd)
 
 	createCmd := &cobra.Command{
 		Use:   "create <name>",
@@ -37,6 +38,7 @@ func init() {
 		Args:  cobra.ExactArgs(1),
 		Run:   runCollectionShow,
 	}
+	addOutputFlag(showCmd)
 
 	deleteCmd := &cobra.Command{
 		Use:   "delete <name>",
@@ -57,6 +59,7 @@ Example:
 	}
 	addCmd.Flags().StringArrayVarP(&headers, "header", "H", []string{}, "Add header")
 	addCmd.Flags().StringVarP(&data, "data", "d", "", "Request body")
+	addCmd.Flags().StringArrayVarP(&queryParams, "query", "q", []string{}, "Add URL query parameter key=value")
 
 	runCmd := &cobra.Command{
 		Use:   "run <name>",
@@ -64,6 +67,7 @@ Example:
 		Args:  cobra.ExactArgs(1),
 		Run:   runCollectionRun,
 	}
+	runCmd.Flags().StringVar(&sessionName, "session", "", "Load and save cookies using the named session")
 
 	collectionCmd.AddCommand(listCmd, createCmd, showCmd, deleteCmd, addCmd, runCmd)
 	rootCmd.AddCommand(collectionCmd)
@@ -82,6 +86,13 @@ func runCollectionList(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
+	if out := outputFromFlags(cmd); !out.IsDefault() {
+		if err := out.Collections(collections); err != nil {
+			format.PrintError(err.Error())
+			os.Exit(1)
+		}
+		return
+	}
 	format.PrintCollectionList(collections)
 }
 
@@ -122,6 +133,13 @@ func runCollectionShow(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
+	if out := outputFromFlags(cmd); !out.IsDefault() {
+		if err := out.Collection(col); err != nil {
+			format.PrintError(err.Error())
+			os.Exit(1)
+		}
+		return
+	}
 	format.PrintCollectionRequests(col)
 }
 
@@ -145,9 +163,20 @@ func runCollectionDelete(cmd *cobra.Command, args []string) {
 func runCollectionAdd(cmd *cobra.Command, args []string) {
 	collectionName := args[0]
 	requestName := args[1]
-	method := args[2]
 	url := args[3]
 
+	method, err := normalizeMethod(args[2])
+	if err != nil {
+		format.PrintError(err.Error())
+		os.Exit(1)
+	}
+
+	query, err := parseQueryFlags(queryParams)
+	if err != nil {
+		format.PrintError(err.Error())
+		os.Exit(1)
+	}
+
 	headerMap := parseHeaders(headers)
 
 	// Filter sensitive headers before storing in collection
@@ -163,6 +192,7 @@ func runCollectionAdd(cmd *cobra.Command, args []string) {
 		Name:    requestName,
 		Method:  method,
 		URL:     url,
+		Query:   query,
 		Headers: filteredHeaders,
 		Body:    data,
 	}
@@ -201,29 +231,75 @@ func runCollectionRun(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
-	client := httpclient.NewClient()
+	// Cookies set by earlier requests (e.g. a login) are sent with later ones;
+	// they are only kept after the run when a session is named
+	var jar *httpclient.SessionJar
+	if sessionName != "" {
+		jar, err = loadSessionJar(sessionName)
+	} else {
+		jar, err = httpclient.NewSessionJar(nil)
+	}
+	if err != nil {
+		format.PrintError(fmt.Sprintf("Failed to load session: %v", err))
+		os.Exit(1)
+	}
+
+	client := httpclient.NewClient(httpclient.WithCookieJar(jar))
 
 	fmt.Printf("Running %d requests from collection '%s'\n\n", len(col.Requests), name)
 
 	for i, req := range col.Requests {
 		// Resolve alias if present
-		resolvedURL := resolveAlias(req.URL)
+		resolvedURL, aliasQuery := resolveAlias(req.URL)
 
 		if req.Name != "" {
 			fmt.Printf("[%d/%d] %s\n", i+1, len(col.Requests), req.Name)
+		} else if req.RequestKind() == model.RequestKindGraphQL {
+			fmt.Printf("[%d/%d] GRAPHQL %s\n", i+1, len(col.Requests), resolvedURL)
 		} else {
 			fmt.Printf("[%d/%d] %s %s\n", i+1, len(col.Requests), req.Method, resolvedURL)
 		}
 
-		resp, err := client.Do(req.Method, resolvedURL, req.Headers, req.Body)
+		sendHeaders, sendBody, err := resolveSecrets(req.Headers, req.Body)
+		if err != nil {
+			format.PrintError(fmt.Sprintf("Failed to resolve secrets: %v", err))
+			continue
+		}
+
+		sendQuery, err := resolveQuery(withDefaultQuery(resolvedURL, aliasQuery, req.Query))
+		if err != nil {
+			format.PrintError(fmt.Sprintf("Failed to resolve query parameters: %v", err))
+			continue
+		}
+		sendURL, err := appendQuery(resolvedURL, sendQuery)
+		if err != nil {
+			format.PrintError(fmt.Sprintf("Invalid URL: %v", err))
+			continue
+		}
+
+		resp, err := client.Do(req.Method, sendURL, sendHeaders, sendBody)
 		if err != nil {
 			format.PrintError(fmt.Sprintf("Request failed: %v", err))
 			continue
 		}
 
-		format.PrintResponse(resp, verbose)
+		switch {
+		case req.RequestKind() == model.RequestKindGraphQL:
+			printGraphQLResponse(resp, verbose)
+		case req.Method == "HEAD":
+			format.PrintResponseHead(resp, true)
+		default:
+			format.PrintResponse(resp, verbose)
+		}
 		fmt.Println()
 	}
 
+	if sessionName != "" {
+		if err := saveSessionJar(sessionName, jar); err != nil {
+			format.PrintError(fmt.Sprintf("Failed to save session: %v", err))
+			os.Exit(1)
+		}
+	}
+
 	format.PrintSuccess(fmt.Sprintf("Completed running collection '%s'", name))
 }
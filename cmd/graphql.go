@@ -0,0 +1,294 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"api/internal/format"
+	httpclient "api/internal/http"
+	"api/internal/model"
+	"api/internal/storage"
+)
+
+var (
+	graphqlQuery      string
+	graphqlVariables  string
+	graphqlOperation  string
+	graphqlIntrospect bool
+	graphqlType       string
+)
+
+func init() {
+	graphqlCmd := &cobra.Command{
+		Use:     "graphql <url>",
+		Aliases: []string{"gql"},
+		Short:   "Send a GraphQL query",
+		Long: `Send a GraphQL query or mutation, or introspect a GraphQL endpoint.
+
+The query is POSTed as {"query": ..., "variables": ..., "operationName": ...}.
+Errors in the response are highlighted and its data is pretty-printed.
+
+Example:
+  apicli graphql myapi/graphql -q '{ viewer { login } }'
+  apicli graphql myapi/graphql -q @user.graphql --vars '{"id": 42}' --operation GetUser
+  apicli graphql myapi/graphql --introspect
+  apicli graphql myapi/graphql --introspect --type User`,
+		Args: cobra.ExactArgs(1),
+		Run:  runGraphQL,
+	}
+	graphqlCmd.Flags().StringVarP(&graphqlQuery, "query", "q", "", "Query document (or @filename, @- for stdin)")
+	graphqlCmd.Flags().StringVar(&graphqlVariables, "vars", "", "Variables as a JSON object (or @filename)")
+	graphqlCmd.Flags().StringVar(&graphqlOperation, "operation", "", "Name of the operation to run when the query defines several")
+	graphqlCmd.Flags().BoolVar(&graphqlIntrospect, "introspect", false, "List the schema's types instead of running a query")
+	graphqlCmd.Flags().StringVar(&graphqlType, "type", "", "With --introspect, show the fields of a single type")
+	graphqlCmd.Flags().StringArrayVarP(&headers, "header", "H", []string{}, "Add header (can be used multiple times)")
+	graphqlCmd.Flags().StringVarP(&saveToCollection, "collection", "c", "", "Save to collection")
+	graphqlCmd.Flags().StringVar(&sessionName, "session", "", "Send and save cookies using the named session")
+	graphqlCmd.Flags().BoolVar(&noHistory, "no-history", false, "Don't save to history")
+	graphqlCmd.Flags().BoolVar(&rawOutput, "raw", false, "Write only the raw response body")
+	graphqlCmd.MarkFlagsMutuallyExclusive("query", "introspect")
+	graphqlCmd.MarkFlagsMutuallyExclusive("query", "type")
+
+	rootCmd.AddCommand(graphqlCmd)
+}
+
+func runGraphQL(cmd *cobra.Command, args []string) {
+	verbose, _ := cmd.Flags().GetBool("verbose")
+	url, aliasQuery := resolveAlias(args[0])
+
+	if graphqlType != "" {
+		graphqlIntrospect = true
+	}
+
+	doc, err := buildGraphQLRequest()
+	if err != nil {
+		format.PrintError(err.Error())
+		os.Exit(1)
+	}
+	body, err := encodeGraphQLRequest(doc)
+	if err != nil {
+		format.PrintError(fmt.Sprintf("Failed to encode query: %v", err))
+		os.Exit(1)
+	}
+
+	// Alias default query parameters apply as for any other request
+	query := withDefaultQuery(url, aliasQuery, nil)
+	fullURL, err := appendQuery(url, query)
+	if err != nil {
+		format.PrintError(fmt.Sprintf("Invalid URL: %v", err))
+		os.Exit(1)
+	}
+	sendQuery, err := resolveQuery(query)
+	if err != nil {
+		format.PrintError(fmt.Sprintf("Failed to resolve query parameters: %v", err))
+		os.Exit(1)
+	}
+	sendURL, err := appendQuery(url, sendQuery)
+	if err != nil {
+		format.PrintError(fmt.Sprintf("Invalid URL: %v", err))
+		os.Exit(1)
+	}
+
+	headerMap := parseHeaders(headers)
+	setGraphQLHeaders(headerMap)
+
+	if !noHistory {
+		warnIfSensitiveBody(body)
+	}
+
+	sendHeaders, sendBody, err := resolveSecrets(headerMap, body)
+	if err != nil {
+		format.PrintError(fmt.Sprintf("Failed to resolve secrets: %v", err))
+		os.Exit(1)
+	}
+
+	var clientOpts []httpclient.Option
+	var jar *httpclient.SessionJar
+	if sessionName != "" {
+		jar, err = loadSessionJar(sessionName)
+		if err != nil {
+			format.PrintError(fmt.Sprintf("Failed to load session: %v", err))
+			os.Exit(1)
+		}
+		clientOpts = append(clientOpts, httpclient.WithCookieJar(jar))
+	}
+
+	client := httpclient.NewClient(clientOpts...)
+	resp, err := client.Do("POST", sendURL, sendHeaders, sendBody)
+	if err != nil {
+		format.PrintError(fmt.Sprintf("Request failed: %v", err))
+		os.Exit(1)
+	}
+
+	if jar != nil {
+		redactSessionCookies(jar)
+		if err := saveSessionJar(sessionName, jar); err != nil {
+			format.PrintError(fmt.Sprintf("Failed to save session: %v", err))
+			os.Exit(1)
+		}
+	}
+
+	switch {
+	case rawOutput:
+		if err := format.PrintRaw(resp); err != nil {
+			format.PrintError(err.Error())
+			os.Exit(1)
+		}
+	case graphqlIntrospect:
+		printIntrospection(resp, verbose)
+	default:
+		printGraphQLResponse(resp, verbose)
+	}
+
+	if !noHistory {
+		saveToHistory("POST", fullURL, headerMap, body, resp)
+	}
+
+	if saveToCollection != "" && !graphqlIntrospect {
+		saveGraphQLToCollection(saveToCollection, url, headerMap, body)
+	}
+}
+
+// buildGraphQLRequest builds the request document from the query flags
+func buildGraphQLRequest() (*model.GraphQLRequest, error) {
+	if graphqlIntrospect {
+		return &model.GraphQLRequest{
+			Query:         httpclient.IntrospectionQuery,
+			OperationName: "IntrospectionQuery",
+		}, nil
+	}
+
+	if graphqlQuery == "" {
+		return nil, fmt.Errorf("a query is required (-q '{ ... }' or -q @query.graphql)")
+	}
+	query, err := readGraphQLArg(graphqlQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read query: %v", err)
+	}
+	if strings.TrimSpace(query) == "" {
+		return nil, fmt.Errorf("the query is empty")
+	}
+
+	doc := &model.GraphQLRequest{Query: query, OperationName: graphqlOperation}
+
+	if graphqlVariables != "" {
+		vars, err := readGraphQLArg(graphqlVariables)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read variables: %v", err)
+		}
+		var object map[string]interface{}
+		if err := json.Unmarshal([]byte(vars), &object); err != nil || object == nil {
+			return nil, fmt.Errorf("--vars must be a JSON object")
+		}
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, []byte(vars)); err != nil {
+			return nil, fmt.Errorf("--vars must be a JSON object")
+		}
+		doc.Variables = compact.Bytes()
+	}
+
+	return doc, nil
+}
+
+// readGraphQLArg returns a flag value, or the contents of the file it names
+// with an @ prefix (@- for stdin)
+func readGraphQLArg(value string) (string, error) {
+	switch {
+	case value == "@-":
+		content, err := io.ReadAll(os.Stdin)
+		return string(content), err
+	case strings.HasPrefix(value, "@"):
+		return readBodyFromFile(strings.TrimPrefix(value, "@"))
+	}
+	return value, nil
+}
+
+// encodeGraphQLRequest encodes a request document without escaping HTML
+// characters, so queries read naturally in history
+func encodeGraphQLRequest(doc *model.GraphQLRequest) (string, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(doc); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// setGraphQLHeaders adds the Content-Type and Accept headers GraphQL servers
+// expect, unless they were given
+func setGraphQLHeaders(headers model.Headers) {
+	if !headers.Has("Content-Type") {
+		headers.Set("Content-Type", "application/json")
+	}
+	if !headers.Has("Accept") {
+		headers.Set("Accept", "application/graphql-response+json, application/json")
+	}
+}
+
+// printGraphQLResponse prints a GraphQL result, or the plain response if the
+// body isn't one
+func printGraphQLResponse(resp *model.Response, verbose bool) {
+	result, ok := httpclient.ParseGraphQLResult(resp.Body)
+	if !ok {
+		format.PrintResponse(resp, verbose)
+		return
+	}
+	format.PrintGraphQLResponse(resp, result, verbose)
+}
+
+// printIntrospection lists the schema's types, or one type's fields with --type
+func printIntrospection(resp *model.Response, verbose bool) {
+	result, ok := httpclient.ParseGraphQLResult(resp.Body)
+	if !ok || result.Data == nil || len(result.Errors) > 0 {
+		printGraphQLResponse(resp, verbose)
+		return
+	}
+
+	schema, err := httpclient.ParseSchema(result.Data)
+	if err != nil {
+		format.PrintError(err.Error())
+		os.Exit(1)
+	}
+
+	if graphqlType == "" {
+		format.PrintGraphQLSchema(schema)
+		return
+	}
+	for i := range schema.Types {
+		if schema.Types[i].Name == graphqlType {
+			format.PrintGraphQLType(&schema.Types[i])
+			return
+		}
+	}
+	format.PrintError(fmt.Sprintf("Type '%s' not found in schema", graphqlType))
+	os.Exit(1)
+}
+
+func saveGraphQLToCollection(collectionName, url string, headers model.Headers, body string) {
+	store, err := storage.NewStorage()
+	if err != nil {
+		format.PrintError(fmt.Sprintf("Failed to save to collection: %v", err))
+		return
+	}
+
+	req := model.SavedRequest{
+		Kind:    model.RequestKindGraphQL,
+		Method:  "POST",
+		URL:     url,
+		Headers: headers,
+		Body:    body,
+	}
+
+	if err := store.AddToCollection(collectionName, req); err != nil {
+		format.PrintError(fmt.Sprintf("Failed to save to collection: %v", err))
+		return
+	}
+
+	format.PrintSuccess(fmt.Sprintf("Saved to collection '%s'", collectionName))
+}
@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"api/internal/format"
+	grpcclient "api/internal/grpc"
+)
+
+var (
+	protoFiles       []string
+	protoImportPaths []string
+	grpcPlaintext    bool
+	grpcDeadline     time.Duration
+)
+
+func init() {
+	grpcCmd := &cobra.Command{
+		Use:   "grpc <host:port> [service[/method]]",
+		Short: "Call a gRPC method",
+		Long: `Call a gRPC method, converting JSON request and response messages to and
+from protobuf. Methods are described by the server's reflection service, or
+by .proto files given with --proto.
+
+With only a host, the server's services are listed; with a service name,
+its methods are listed.
+
+Request messages are given with -d as JSON. Client-streaming methods take a
+sequence of JSON objects; with -d @- they are sent as they are read from stdin.
+Response messages are printed as they arrive.
+
+Example:
+  apicli grpc api.example.com:443
+  apicli grpc api.example.com:443 users.v1.UserService
+  apicli grpc api.example.com:443 users.v1.UserService/GetUser -d '{"id": 42}' -H "authorization: Bearer {{secret:token}}"
+  apicli grpc localhost:50051 chat.Chat/Stream --plaintext --proto chat.proto -d @-`,
+		Args: cobra.RangeArgs(1, 2),
+		Run:  runGRPC,
+	}
+	grpcCmd.Flags().StringVarP(&data, "data", "d", "", "Request message(s) as JSON (or @filename, @- for stdin)")
+	grpcCmd.Flags().StringArrayVarP(&headers, "header", "H", []string{}, "Add request metadata key: value (can be used multiple times)")
+	grpcCmd.Flags().StringArrayVar(&protoFiles, "proto", []string{}, "Describe services with a .proto file instead of server reflection (can be used multiple times)")
+	grpcCmd.Flags().StringArrayVarP(&protoImportPaths, "import-path", "I", []string{}, "Directory to search for .proto files and their imports (can be used multiple times)")
+	grpcCmd.Flags().BoolVar(&grpcPlaintext, "plaintext", false, "Connect without TLS")
+	grpcCmd.Flags().DurationVar(&grpcDeadline, "deadline", 0, "Fail the call if it takes longer than this (e.g. 5s; 0 for no deadline)")
+	grpcCmd.Flags().BoolVar(&noHistory, "no-history", false, "Don't save to history")
+
+	rootCmd.AddCommand(grpcCmd)
+}
+
+func runGRPC(cmd *cobra.Command, args []string) {
+	verbose, _ := cmd.Flags().GetBool("verbose")
+	target := args[0]
+
+	client, err := grpcclient.Dial(target, grpcclient.Options{
+		Plaintext:   grpcPlaintext,
+		ProtoFiles:  protoFiles,
+		ImportPaths: protoImportPaths,
+	})
+	if err != nil {
+		format.PrintError(err.Error())
+		os.Exit(1)
+	}
+	defer client.Close()
+
+	// Ctrl-C ends streaming calls; what was received so far is kept
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if len(args) == 1 {
+		services, err := client.ListServices(ctx)
+		if err != nil {
+			format.PrintError(fmt.Sprintf("Failed to list services: %v", err))
+			os.Exit(1)
+		}
+		format.PrintGRPCServices(services)
+		return
+	}
+
+	method := args[1]
+	if !strings.Contains(method, "/") {
+		svc, err := client.DescribeService(ctx, method)
+		if err != nil {
+			format.PrintError(fmt.Sprintf("Failed to describe service: %v", err))
+			os.Exit(1)
+		}
+		format.PrintGRPCService(svc)
+		return
+	}
+
+	headerMap := parseHeaders(headers)
+
+	// Messages from stdin are streamed as they are read and recorded as they go
+	var input io.Reader
+	body := data
+	var streamed *bytes.Buffer
+	switch {
+	case data == "@-":
+		streamed = &bytes.Buffer{}
+		input = io.TeeReader(os.Stdin, streamed)
+		body = ""
+	case strings.HasPrefix(data, "@"):
+		body, err = readBodyFromFile(strings.TrimPrefix(data, "@"))
+		if err != nil {
+			format.PrintError(fmt.Sprintf("Failed to read file: %v", err))
+			os.Exit(1)
+		}
+	}
+
+	if !noHistory {
+		warnIfSensitiveBody(body)
+	}
+
+	sendHeaders, sendBody, err := resolveSecrets(headerMap, body)
+	if err != nil {
+		format.PrintError(fmt.Sprintf("Failed to resolve secrets: %v", err))
+		os.Exit(1)
+	}
+	if input == nil && sendBody != "" {
+		input = strings.NewReader(sendBody)
+	}
+
+	resp, err := client.Call(ctx, method, grpcclient.CallOptions{
+		Metadata:  sendHeaders,
+		Timeout:   grpcDeadline,
+		Input:     input,
+		OnMessage: format.PrintGRPCMessage,
+	})
+	if err != nil {
+		format.PrintError(fmt.Sprintf("Call failed: %v", err))
+		os.Exit(1)
+	}
+	if streamed != nil {
+		body = streamed.String()
+	}
+
+	format.PrintGRPCStatus(resp, verbose)
+
+	if !noHistory {
+		scheme := "grpcs"
+		if grpcPlaintext {
+			scheme = "grpc"
+		}
+		saveToHistory("GRPC", fmt.Sprintf("%s://%s/%s", scheme, target, method), headerMap, body, resp)
+	}
+}
@@ -3,10 +3,14 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"regexp"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"api/internal/format"
+	"api/internal/model"
 	"api/internal/storage"
 )
 
@@ -18,6 +22,10 @@ func init() {
 	}
 
 	historyCmd.Flags().IntP("limit", "n", 10, "Number of requests to show")
+	historyCmd.Flags().StringArray("tag", []string{}, "Only show requests with this tag (can be used multiple times)")
+	historyCmd.Flags().String("note", "", "Only show requests whose note contains this text")
+	historyCmd.Flags().Bool("pinned", false, "Only show pinned requests")
+	addOutputFlag(historyCmd)
 
 	showCmd := &cobra.Command{
 		Use:   "show <id or index>",
@@ -25,6 +33,10 @@ func init() {
 		Args:  cobra.ExactArgs(1),
 		Run:   runHistoryShow,
 	}
+	showCmd.Flags().Bool("hexdump", false, "Show binary bodies as a hex dump")
+	showCmd.Flags().Bool("raw", false, "Write only the raw response body (for piping binary data)")
+	addOutputFlag(showCmd)
+	showCmd.Flags().String("filter", "", "Show only the parts of a JSON response selected by a jq expression (or JSONPath starting with $)")
 
 	clearCmd := &cobra.Command{
 		Use:   "clear",
@@ -32,7 +44,58 @@ func init() {
 		Run:   runHistoryClear,
 	}
 
-	historyCmd.AddCommand(showCmd, clearCmd)
+	statsCmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Show request counts, error rates, latency and sizes per endpoint",
+		Long: `Aggregate history by host, endpoint and method. Path segments that look
+like IDs are grouped, so /users/42 and /users/7 count as /users/:id.
+
+Example:
+  apicli history stats
+  apicli history stats --since 24h
+  apicli history stats --since 2024-05-01 --output json`,
+		Args: cobra.NoArgs,
+		Run:  runHistoryStats,
+	}
+	statsCmd.Flags().String("since", "", "Only include requests from this long ago (e.g. 30m, 24h, 7d) or since a date (2006-01-02)")
+	addOutputFlag(statsCmd)
+
+	tagCmd := &cobra.Command{
+		Use:   "tag <id or index> <tag> [tags...]",
+		Short: "Tag a request in history",
+		Long: `Tag a request so it can be found with history --tag.
+
+Example:
+  apicli history tag 1 login
+  apicli history tag a1b2c3d4 bug-1234 staging
+  apicli history tag 1 staging --remove`,
+		Args: cobra.MinimumNArgs(2),
+		Run:  runHistoryTag,
+	}
+	tagCmd.Flags().Bool("remove", false, "Remove the tags instead of adding them")
+
+	noteCmd := &cobra.Command{
+		Use:   "note <id or index> <text>",
+		Short: "Attach a note to a request in history (an empty note removes it)",
+		Args:  cobra.ExactArgs(2),
+		Run:   runHistoryNote,
+	}
+
+	pinCmd := &cobra.Command{
+		Use:   "pin <id or index>",
+		Short: "Keep a request in history when older requests are pruned",
+		Args:  cobra.ExactArgs(1),
+		Run:   runHistoryPin(true),
+	}
+
+	unpinCmd := &cobra.Command{
+		Use:   "unpin <id or index>",
+		Short: "Let a pinned request be pruned again",
+		Args:  cobra.ExactArgs(1),
+		Run:   runHistoryPin(false),
+	}
+
+	historyCmd.AddCommand(showCmd, statsCmd, tagCmd, noteCmd, pinCmd, unpinCmd, clearCmd)
 	rootCmd.AddCommand(historyCmd)
 }
 
@@ -43,17 +106,78 @@ func runHistoryList(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
-	history, err := store.LoadHistory()
+	var filter model.HistoryFilter
+	filter.Tags, _ = cmd.Flags().GetStringArray("tag")
+	filter.Note, _ = cmd.Flags().GetString("note")
+	filter.Pinned, _ = cmd.Flags().GetBool("pinned")
+
+	history, err := store.SearchHistory(filter)
 	if err != nil {
 		format.PrintError(fmt.Sprintf("Failed to load history: %v", err))
 		os.Exit(1)
 	}
 
+	// A filtered list keeps the numbers history show uses for the full list
+	var positions []int
+	if len(filter.Tags) > 0 || filter.Note != "" || filter.Pinned {
+		all, err := store.LoadHistory()
+		if err != nil {
+			format.PrintError(fmt.Sprintf("Failed to load history: %v", err))
+			os.Exit(1)
+		}
+		positions = historyPositions(all, history.Requests)
+	}
+
 	limit, _ := cmd.Flags().GetInt("limit")
-	format.PrintHistoryList(history.Requests, limit)
+	if out := outputFromFlags(cmd); !out.IsDefault() {
+		if err := out.History(history.Requests, positions, limit); err != nil {
+			format.PrintError(err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+	format.PrintHistoryList(history.Requests, positions, limit)
+}
+
+// historyPositions returns the 1-based position of each request in the full history
+func historyPositions(all *model.History, requests []model.Request) []int {
+	index := make(map[string]int, len(all.Requests))
+	for i, req := range all.Requests {
+		index[req.ID] = i + 1
+	}
+	positions := make([]int, len(requests))
+	for i, req := range requests {
+		positions[i] = index[req.ID]
+	}
+	return positions
 }
 
 func runHistoryShow(cmd *cobra.Command, args []string) {
+	out := outputFromFlags(cmd)
+	_, req := loadHistoryRequest(args[0])
+	printHistoryRequest(cmd, out, req)
+}
+
+// findHistoryRequest finds a request by its 1-based index or its ID
+func findHistoryRequest(history *model.History, identifier string) *model.Request {
+	// Try to parse as index first (1-based)
+	if index, err := strconv.Atoi(identifier); err == nil {
+		if index > 0 && index <= len(history.Requests) {
+			return &history.Requests[index-1]
+		}
+	}
+
+	// Try to find by ID
+	for i := range history.Requests {
+		if history.Requests[i].ID == identifier {
+			return &history.Requests[i]
+		}
+	}
+	return nil
+}
+
+// loadHistoryRequest opens storage and finds a request by index or ID, exiting if it isn't there
+func loadHistoryRequest(identifier string) (*storage.SQLiteStorage, *model.Request) {
 	store, err := storage.NewStorage()
 	if err != nil {
 		format.PrintError(fmt.Sprintf("Failed to load history: %v", err))
@@ -66,26 +190,161 @@ func runHistoryShow(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
-	identifier := args[0]
+	req := findHistoryRequest(history, identifier)
+	if req == nil {
+		format.PrintError(fmt.Sprintf("Request not found: %s", identifier))
+		os.Exit(1)
+	}
+	return store, req
+}
 
-	// Try to parse as index first (1-based)
-	if index, err := strconv.Atoi(identifier); err == nil {
-		if index > 0 && index <= len(history.Requests) {
-			format.PrintRequestDetail(&history.Requests[index-1])
-			return
+// printHistoryRequest prints a history entry in the chosen output format.
+// --filter narrows the stored response body.
+func printHistoryRequest(cmd *cobra.Command, out format.Output, req *model.Request) {
+	expr, _ := cmd.Flags().GetString("filter")
+	bodyFilter, err := parseFilter(expr)
+	if err != nil {
+		format.PrintError(err.Error())
+		os.Exit(1)
+	}
+	if bodyFilter != nil {
+		if req.Response == nil {
+			format.PrintError("Request has no stored response")
+			os.Exit(1)
+		}
+		filtered, err := filterResponse(bodyFilter, req.Response)
+		if err != nil {
+			format.PrintError(fmt.Sprintf("Filter failed: %v", err))
+			os.Exit(1)
 		}
+		shown := *req
+		shown.Response = filtered
+		req = &shown
 	}
 
-	// Try to find by ID
-	for _, req := range history.Requests {
-		if req.ID == identifier {
-			format.PrintRequestDetail(&req)
-			return
+	if !out.IsDefault() {
+		if err := out.Request(req); err != nil {
+			format.PrintError(err.Error())
+			os.Exit(1)
 		}
+		return
 	}
 
-	format.PrintError(fmt.Sprintf("Request not found: %s", identifier))
-	os.Exit(1)
+	format.Hexdump, _ = cmd.Flags().GetBool("hexdump")
+	format.Page(func() { format.PrintRequestDetail(req) })
+}
+
+func runHistoryStats(cmd *cobra.Command, args []string) {
+	out := outputFromFlags(cmd)
+
+	sinceFlag, _ := cmd.Flags().GetString("since")
+	since, err := parseSince(sinceFlag, time.Now())
+	if err != nil {
+		format.PrintError(err.Error())
+		os.Exit(1)
+	}
+
+	store, err := storage.NewStorage()
+	if err != nil {
+		format.PrintError(fmt.Sprintf("Failed to load history: %v", err))
+		os.Exit(1)
+	}
+
+	stats, err := store.HistoryStats(since)
+	if err != nil {
+		format.PrintError(fmt.Sprintf("Failed to compute statistics: %v", err))
+		os.Exit(1)
+	}
+
+	if !out.IsDefault() {
+		if err := out.HistoryStats(stats); err != nil {
+			format.PrintError(err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+	format.PrintHistoryStats(stats)
+}
+
+// parseSince parses --since as a duration before now (Go syntax, plus d for
+// days) or as a date or time; an empty value means no limit
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q (use a duration such as 24h or 7d, or a date such as 2006-01-02)", value)
+}
+
+// tagPattern matches a valid history tag
+var tagPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.:/-]*$`)
+
+func runHistoryTag(cmd *cobra.Command, args []string) {
+	tags := args[1:]
+	for _, tag := range tags {
+		if !tagPattern.MatchString(tag) {
+			format.PrintError(fmt.Sprintf("Invalid tag %q: use letters, digits and _ . : / -", tag))
+			os.Exit(1)
+		}
+	}
+
+	store, req := loadHistoryRequest(args[0])
+	remove, _ := cmd.Flags().GetBool("remove")
+	if remove {
+		if err := store.RemoveHistoryTags(req.ID, tags...); err != nil {
+			format.PrintError(fmt.Sprintf("Failed to remove tags: %v", err))
+			os.Exit(1)
+		}
+		format.PrintSuccess(fmt.Sprintf("Removed %s from %s", strings.Join(tags, ", "), req.ID))
+		return
+	}
+
+	if err := store.AddHistoryTags(req.ID, tags...); err != nil {
+		format.PrintError(fmt.Sprintf("Failed to tag request: %v", err))
+		os.Exit(1)
+	}
+	format.PrintSuccess(fmt.Sprintf("Tagged %s with %s", req.ID, strings.Join(tags, ", ")))
+}
+
+func runHistoryNote(cmd *cobra.Command, args []string) {
+	store, req := loadHistoryRequest(args[0])
+	note := strings.TrimSpace(args[1])
+	if err := store.SetHistoryNote(req.ID, note); err != nil {
+		format.PrintError(fmt.Sprintf("Failed to save note: %v", err))
+		os.Exit(1)
+	}
+	if note == "" {
+		format.PrintSuccess(fmt.Sprintf("Removed the note from %s", req.ID))
+		return
+	}
+	format.PrintSuccess(fmt.Sprintf("Saved note on %s", req.ID))
+}
+
+func runHistoryPin(pinned bool) func(cmd *cobra.Command, args []string) {
+	return func(cmd *cobra.Command, args []string) {
+		store, req := loadHistoryRequest(args[0])
+		if err := store.SetHistoryPinned(req.ID, pinned); err != nil {
+			format.PrintError(fmt.Sprintf("Failed to update request: %v", err))
+			os.Exit(1)
+		}
+		if pinned {
+			format.PrintSuccess(fmt.Sprintf("Pinned %s; it will be kept when older history is pruned", req.ID))
+		} else {
+			format.PrintSuccess(fmt.Sprintf("Unpinned %s", req.ID))
+		}
+	}
 }
 
 func runHistoryClear(cmd *cobra.Command, args []string) {
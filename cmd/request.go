@@ -1,14 +1,26 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
 	"fmt"
+	"hash"
+	"io"
+	"mime"
+	neturl "net/url"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/spf13/cobra"
+	"api/internal/filter"
 	"api/internal/format"
 	httpclient "api/internal/http"
 	"api/internal/model"
@@ -59,14 +71,43 @@ var (
 	data        string
 	noHistory   bool
 	saveToCollection string
+	formFields  []string
+	formURLEncoded bool
+	contentType string
+	uploadFile  string
+	outputFile  string
+	download    bool
+	resumeDownload bool
+	hexdump     bool
+	rawOutput   bool
+	followRedirects bool
+	noFollow    bool
+	maxRedirects int
+	redirectAuth string
+	useHTTP1    bool
+	useHTTP2    bool
+	useH2C      bool
+	requestMethod string
+	queryParams []string
+	streamEvents bool
+	lastEventID string
+	reconnectStream bool
+	filterExpr  string
+	outputFormat string
+	compressed  string
+	compressBody string
 )
 
+// maxHistoryBodySize is the largest request body stored verbatim in history;
+// larger bodies are recorded as a size and hash summary
+const maxHistoryBodySize = 64 * 1024
+
 func init() {
 	// GET command
 	getCmd := &cobra.Command{
-		Use:   "get <url>",
+		Use:   "get <url> [items...]",
 		Short: "Send a GET request",
-		Args:  cobra.ExactArgs(1),
+		Args:  cobra.MinimumNArgs(1),
 		Run:   runRequest("GET"),
 	}
 	addRequestFlags(getCmd)
@@ -74,9 +115,9 @@ func init() {
 
 	// POST command
 	postCmd := &cobra.Command{
-		Use:   "post <url>",
+		Use:   "post <url> [items...]",
 		Short: "Send a POST request",
-		Args:  cobra.ExactArgs(1),
+		Args:  cobra.MinimumNArgs(1),
 		Run:   runRequest("POST"),
 	}
 	addRequestFlags(postCmd)
@@ -84,9 +125,9 @@ func init() {
 
 	// PUT command
 	putCmd := &cobra.Command{
-		Use:   "put <url>",
+		Use:   "put <url> [items...]",
 		Short: "Send a PUT request",
-		Args:  cobra.ExactArgs(1),
+		Args:  cobra.MinimumNArgs(1),
 		Run:   runRequest("PUT"),
 	}
 	addRequestFlags(putCmd)
@@ -94,9 +135,9 @@ func init() {
 
 	// PATCH command
 	patchCmd := &cobra.Command{
-		Use:   "patch <url>",
+		Use:   "patch <url> [items...]",
 		Short: "Send a PATCH request",
-		Args:  cobra.ExactArgs(1),
+		Args:  cobra.MinimumNArgs(1),
 		Run:   runRequest("PATCH"),
 	}
 	addRequestFlags(patchCmd)
@@ -104,20 +145,101 @@ func init() {
 
 	// DELETE command
 	deleteCmd := &cobra.Command{
-		Use:   "delete <url>",
+		Use:   "delete <url> [items...]",
 		Short: "Send a DELETE request",
-		Args:  cobra.ExactArgs(1),
+		Args:  cobra.MinimumNArgs(1),
 		Run:   runRequest("DELETE"),
 	}
 	addRequestFlags(deleteCmd)
 	rootCmd.AddCommand(deleteCmd)
+
+	// HEAD command
+	headCmd := &cobra.Command{
+		Use:   "head <url> [items...]",
+		Short: "Send a HEAD request (shows response headers only)",
+		Args:  cobra.MinimumNArgs(1),
+		Run:   runRequest("HEAD"),
+	}
+	addRequestFlags(headCmd)
+	rootCmd.AddCommand(headCmd)
+
+	// OPTIONS command
+	optionsCmd := &cobra.Command{
+		Use:   "options <url> [items...]",
+		Short: "Send an OPTIONS request",
+		Args:  cobra.MinimumNArgs(1),
+		Run:   runRequest("OPTIONS"),
+	}
+	addRequestFlags(optionsCmd)
+	rootCmd.AddCommand(optionsCmd)
+
+	// Generic command for any other method (PROPFIND, PURGE, QUERY, ...)
+	requestCmd := &cobra.Command{
+		Use:   "request <url> [items...]",
+		Short: "Send a request with any method",
+		Long: `Send a request with any HTTP method, including WebDAV and custom verbs.
+
+Example:
+  apicli request -X PROPFIND https://dav.example.com/files/ -H "Depth: 1"
+  apicli request -X PURGE https://cdn.example.com/assets/app.js`,
+		Args: cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			method, err := normalizeMethod(requestMethod)
+			if err != nil {
+				format.PrintError(err.Error())
+				os.Exit(1)
+			}
+			runRequest(method)(cmd, args)
+		},
+	}
+	requestCmd.Flags().StringVarP(&requestMethod, "method", "X", "GET", "HTTP method to send")
+	addRequestFlags(requestCmd)
+	rootCmd.AddCommand(requestCmd)
+}
+
+// methodPattern matches a valid HTTP method token (RFC 9110 section 9.1)
+var methodPattern = regexp.MustCompile("^[!#$%&'*+.^_`|~0-9A-Za-z-]+$")
+
+// normalizeMethod validates an HTTP method and converts it to upper case
+func normalizeMethod(method string) (string, error) {
+	if !methodPattern.MatchString(method) {
+		return "", fmt.Errorf("invalid HTTP method %q", method)
+	}
+	return strings.ToUpper(method), nil
 }
 
 func addRequestFlags(cmd *cobra.Command) {
 	cmd.Flags().StringArrayVarP(&headers, "header", "H", []string{}, "Add header (can be used multiple times)")
 	cmd.Flags().StringVarP(&data, "data", "d", "", "Request body (JSON string or @filename)")
+	cmd.Flags().StringArrayVarP(&queryParams, "query", "q", []string{}, "Add URL query parameter key=value (can be used multiple times)")
 	cmd.Flags().BoolVar(&noHistory, "no-history", false, "Don't save to history")
 	cmd.Flags().StringVarP(&saveToCollection, "collection", "c", "", "Save to collection")
+	cmd.Flags().StringArrayVarP(&formFields, "field", "F", []string{}, "Add form field: name=value or name=@file[;type=mime] (can be used multiple times)")
+	cmd.Flags().BoolVar(&formURLEncoded, "form", false, "Send form fields URL-encoded instead of multipart/form-data")
+	cmd.Flags().StringVarP(&uploadFile, "upload-file", "T", "", "Stream request body from a file (or - for stdin) without loading it into memory")
+	cmd.Flags().StringVarP(&outputFile, "output-file", "o", "", "Stream the response body to a file instead of printing it (use --output to choose a format)")
+	cmd.Flags().BoolVar(&download, "download", false, "Save the response body to a file named by Content-Disposition or the URL")
+	cmd.Flags().BoolVarP(&resumeDownload, "continue", "C", false, "Resume a partial download with a Range request")
+	cmd.Flags().BoolVar(&hexdump, "hexdump", false, "Show binary response bodies as a hex dump")
+	cmd.Flags().BoolVar(&rawOutput, "raw", false, "Write only the raw response body (for piping binary data)")
+	cmd.Flags().StringVar(&outputFormat, "output", "", "Output format: raw, json, yaml, table or template=<go-template>")
+	cmd.Flags().StringVar(&filterExpr, "filter", "", "Show only the parts of a JSON response selected by a jq expression (or JSONPath starting with $)")
+	cmd.Flags().BoolVar(&followRedirects, "follow", true, "Follow redirects")
+	cmd.Flags().BoolVar(&noFollow, "no-follow", false, "Don't follow redirects; show the 3xx response itself")
+	cmd.Flags().IntVar(&maxRedirects, "max-redirs", httpclient.DefaultMaxRedirects, "Maximum number of redirects to follow")
+	cmd.Flags().StringVar(&redirectAuth, "redirect-auth", string(httpclient.RedirectAuthSameHost), "Re-send Authorization and Cookie headers on redirects: same-host, always or never")
+	cmd.Flags().BoolVar(&useHTTP1, "http1.1", false, "Use HTTP/1.1 even if the server supports HTTP/2")
+	cmd.Flags().BoolVar(&useHTTP2, "http2", false, "Require HTTP/2 (over TLS)")
+	cmd.Flags().BoolVar(&useH2C, "http2-prior-knowledge", false, "Use HTTP/2 without negotiation (h2c for http:// URLs)")
+	cmd.MarkFlagsMutuallyExclusive("http1.1", "http2", "http2-prior-knowledge")
+	cmd.Flags().BoolVar(&streamEvents, "stream", false, "Treat the response as a server-sent event stream even without a text/event-stream Content-Type")
+	cmd.Flags().StringVar(&lastEventID, "last-event-id", "", "Resume an event stream from this event ID")
+	cmd.Flags().BoolVar(&reconnectStream, "reconnect", false, "Reconnect when an event stream closes, resuming from the last event ID")
+	cmd.Flags().StringVar(&sessionName, "session", "", "Send and save cookies using the named session")
+	cmd.Flags().StringVar(&compressed, "compressed", "", "Ask for a compressed response; pick encodings with --compressed=br,zstd (default gzip, deflate, br, zstd)")
+	cmd.Flags().Lookup("compressed").NoOptDefVal = httpclient.DefaultAcceptEncoding
+	cmd.Flags().StringVar(&compressBody, "compress-body", "", "Compress the request body: gzip, deflate, br or zstd")
+	cmd.Flags().StringVar(&contentType, "content-type", "", "Request Content-Type (or json, xml, form, text, html); detected from the body if omitted")
 }
 
 func runRequest(method string) func(cmd *cobra.Command, args []string) {
@@ -125,17 +247,92 @@ func runRequest(method string) func(cmd *cobra.Command, args []string) {
 		url := args[0]
 		verbose, _ := cmd.Flags().GetBool("verbose")
 
+		// Check the output format and filter before sending anything
+		out, err := parseOutputFormat(outputFormat, rawOutput)
+		if err != nil {
+			format.PrintError(err.Error())
+			os.Exit(1)
+		}
+		if err := checkOutputFile(outputFile); err != nil {
+			format.PrintError(err.Error())
+			os.Exit(1)
+		}
+		bodyFilter, err := parseFilter(filterExpr)
+		if err != nil {
+			format.PrintError(err.Error())
+			os.Exit(1)
+		}
+		if bodyFilter != nil && (outputFile != "" || download || resumeDownload) {
+			format.PrintError("Cannot combine --filter with downloads")
+			os.Exit(1)
+		}
+		acceptEncoding, err := parseCompressed(compressed)
+		if err != nil {
+			format.PrintError(err.Error())
+			os.Exit(1)
+		}
+		if compressBody != "" {
+			if _, err := httpclient.ParseEncoding(compressBody); err != nil {
+				format.PrintError(err.Error())
+				os.Exit(1)
+			}
+		}
+
 		// Resolve alias if present
-		url = resolveAlias(url)
+		url, aliasQuery := resolveAlias(url)
+
+		// Parse httpie-style request items (name=value, Header:Value, q==search, ...)
+		items, err := parseRequestItems(args[1:])
+		if err != nil {
+			format.PrintError(fmt.Sprintf("Invalid request item: %v", err))
+			os.Exit(1)
+		}
+
+		// Query parameters: -q flags, then q==value items, after the alias defaults
+		query, err := parseQueryFlags(queryParams)
+		if err != nil {
+			format.PrintError(err.Error())
+			os.Exit(1)
+		}
+		query = withDefaultQuery(url, aliasQuery, append(query, items.query...))
+
+		// History shows {{env:...}} and {{secret:...}} references; only the sent URL resolves them
+		fullURL, err := appendQuery(url, query)
+		if err != nil {
+			format.PrintError(fmt.Sprintf("Invalid URL: %v", err))
+			os.Exit(1)
+		}
+		sendQuery, err := resolveQuery(query)
+		if err != nil {
+			format.PrintError(fmt.Sprintf("Failed to resolve query parameters: %v", err))
+			os.Exit(1)
+		}
+		sendURL, err := appendQuery(url, sendQuery)
+		if err != nil {
+			format.PrintError(fmt.Sprintf("Invalid URL: %v", err))
+			os.Exit(1)
+		}
 
-		// Parse headers
+		// Parse headers; headers given as items take precedence over -H
 		headerMap := parseHeaders(headers)
+		for k, values := range items.headers {
+			headerMap.Del(k)
+			headerMap[k] = values
+		}
 
 		// Read body from file if prefixed with @
 		body := data
-		if strings.HasPrefix(body, "@") {
-			filename := strings.TrimPrefix(body, "@")
-			content, err := readBodyFromFile(filename)
+		var bodyFile string
+		if body == "@-" {
+			content, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				format.PrintError(fmt.Sprintf("Failed to read stdin: %v", err))
+				os.Exit(1)
+			}
+			body = string(content)
+		} else if strings.HasPrefix(body, "@") {
+			bodyFile = strings.TrimPrefix(body, "@")
+			content, err := readBodyFromFile(bodyFile)
 			if err != nil {
 				format.PrintError(fmt.Sprintf("Failed to read file: %v", err))
 				os.Exit(1)
@@ -143,48 +340,784 @@ func runRequest(method string) func(cmd *cobra.Command, args []string) {
 			body = content
 		}
 
+		// Streamed uploads replace every other kind of body
+		var upload *streamedUpload
+		if uploadFile != "" {
+			if data != "" || len(formFields) > 0 || items.body != nil {
+				format.PrintError("Cannot combine --upload-file with --data, form fields or data items")
+				os.Exit(1)
+			}
+			upload, err = openUpload(uploadFile)
+			if err != nil {
+				format.PrintError(fmt.Sprintf("Failed to open upload: %v", err))
+				os.Exit(1)
+			}
+			if contentType != "" {
+				headerMap.Set("Content-Type", httpclient.ExpandContentType(contentType))
+			}
+		}
+
+		// Data items build a JSON body
+		if items.body != nil {
+			if data != "" || len(formFields) > 0 {
+				format.PrintError("Cannot combine data items with --data or form fields")
+				os.Exit(1)
+			}
+			body, err = encodeItemsBody(items.body)
+			if err != nil {
+				format.PrintError(fmt.Sprintf("Failed to encode body: %v", err))
+				os.Exit(1)
+			}
+		}
+
+		// Form fields replace the body; history records the fields, not file contents
+		if len(formFields) > 0 {
+			if data != "" {
+				format.PrintError("Cannot combine --data with form fields")
+				os.Exit(1)
+			}
+			body = strings.Join(formFields, "\n")
+		}
+
+		// Set the Content-Type and make sure JSON bodies are well-formed before sending
+		if contentType != "" {
+			headerMap.Set("Content-Type", httpclient.ExpandContentType(contentType))
+		}
+		if body != "" && len(formFields) == 0 {
+			if err := prepareBodyContentType(headerMap, body, bodyFile); err != nil {
+				format.PrintError(err.Error())
+				os.Exit(1)
+			}
+		}
+
 		// Warn if body contains potentially sensitive data
 		if !noHistory {
 			warnIfSensitiveBody(body)
 		}
 
+		// Resolve {{secret:name}} references only for sending
+		sendHeaders, sendBody, err := resolveSecrets(headerMap, body)
+		if err != nil {
+			format.PrintError(fmt.Sprintf("Failed to resolve secrets: %v", err))
+			os.Exit(1)
+		}
+
+		var reqBody *httpclient.Body
+		switch {
+		case len(formFields) > 0:
+			reqBody, err = buildFormBody(formFields, formURLEncoded)
+			if err != nil {
+				format.PrintError(fmt.Sprintf("Invalid form data: %v", err))
+				os.Exit(1)
+			}
+		case upload != nil:
+			reqBody = upload.Body()
+		case sendBody != "":
+			reqBody = httpclient.NewStringBody(sendBody)
+		}
+		if compressBody != "" {
+			if reqBody == nil {
+				format.PrintError("--compress-body needs a request body")
+				os.Exit(1)
+			}
+			reqBody, err = httpclient.CompressBody(reqBody, compressBody)
+			if err != nil {
+				format.PrintError(fmt.Sprintf("Failed to compress body: %v", err))
+				os.Exit(1)
+			}
+		}
+
+		policy, err := redirectPolicy()
+		if err != nil {
+			format.PrintError(err.Error())
+			os.Exit(1)
+		}
+		clientOpts := []httpclient.Option{
+			httpclient.WithRedirectPolicy(policy),
+			httpclient.WithProtocol(requestProtocol()),
+		}
+		if acceptEncoding != "" {
+			clientOpts = append(clientOpts, httpclient.WithAcceptEncoding(acceptEncoding))
+		}
+
+		// Sessions carry cookies from earlier requests
+		var jar *httpclient.SessionJar
+		if sessionName != "" {
+			jar, err = loadSessionJar(sessionName)
+			if err != nil {
+				format.PrintError(fmt.Sprintf("Failed to load session: %v", err))
+				os.Exit(1)
+			}
+			clientOpts = append(clientOpts, httpclient.WithCookieJar(jar))
+		}
+
 		// Create HTTP client and make request
-		client := httpclient.NewClient()
-		resp, err := client.Do(method, url, headerMap, body)
+		client := httpclient.NewClient(clientOpts...)
+		var resp *model.Response
+		if outputFile != "" || download || resumeDownload {
+			resp, err = client.Download(method, sendURL, sendHeaders, reqBody, httpclient.DownloadOptions{
+				Path:   outputFile,
+				Resume: resumeDownload,
+				Progress: func(w io.Writer, start, total int64) io.Writer {
+					return format.NewProgressWriter(w, "Downloading", start, total)
+				},
+			})
+		} else {
+			// Event streams are printed as they arrive; Ctrl-C ends them and keeps the transcript
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			resp, err = client.Stream(method, sendURL, sendHeaders, reqBody, eventStreamOptions(ctx, verbose, out))
+			stop()
+		}
+		if upload != nil {
+			body = upload.Summary()
+		}
 		if err != nil {
 			format.PrintError(fmt.Sprintf("Request failed: %v", err))
 			os.Exit(1)
 		}
 
-		// Print response
-		format.PrintResponse(resp, verbose)
+		if jar != nil {
+			redactSessionCookies(jar)
+			if err := saveSessionJar(sessionName, jar); err != nil {
+				format.PrintError(fmt.Sprintf("Failed to save session: %v", err))
+				os.Exit(1)
+			}
+		}
+
+		// Print response; history keeps the whole body even when it is filtered
+		shown := resp
+		if bodyFilter != nil && !resp.Streamed && method != "HEAD" {
+			shown, err = filterResponse(bodyFilter, resp)
+			if err != nil {
+				format.PrintError(fmt.Sprintf("Filter failed: %v", err))
+			}
+		}
+		switch {
+		case resp.Streamed && out.IsDefault():
+			format.PrintStreamNotice(fmt.Sprintf("stream closed after %.1fs", float64(resp.DurationMs)/1000))
+		case resp.Streamed && out.IsRaw():
+			// Each event's data was printed as it arrived
+		case shown == nil:
+			// The filter failed; the request is still recorded before exiting
+		case !out.IsDefault():
+			if err := out.Response(shown); err != nil {
+				format.PrintError(err.Error())
+				os.Exit(1)
+			}
+		default:
+			format.Hexdump = hexdump
+			if method == "HEAD" {
+				format.PrintResponseHead(shown, true)
+			} else {
+				format.Page(func() { format.PrintResponse(shown, verbose) })
+			}
+		}
 
 		// Save to history unless disabled
 		if !noHistory {
-			saveToHistory(method, url, headerMap, body, resp)
+			saveToHistory(method, fullURL, headerMap, body, resp)
 		}
 
 		// Save to collection if specified
 		if saveToCollection != "" {
-			saveRequestToCollection(saveToCollection, method, url, headerMap, body)
+			if len(formFields) > 0 {
+				saveFormToCollection(saveToCollection, method, url, query, headerMap)
+			} else {
+				saveRequestToCollection(saveToCollection, method, url, query, headerMap, body)
+			}
+		}
+
+		if shown == nil {
+			os.Exit(1)
+		}
+	}
+}
+
+// parseOutputFormat parses --output, treating --raw as --output raw
+func parseOutputFormat(output string, raw bool) (format.Output, error) {
+	if raw {
+		if output != "" && output != format.OutputRaw {
+			return format.Output{}, fmt.Errorf("cannot combine --raw with --output %s", output)
 		}
+		output = format.OutputRaw
 	}
+	return format.ParseOutput(output)
 }
 
-func parseHeaders(headerStrings []string) map[string]string {
-	result := make(map[string]string)
+// checkOutputFile rejects -o values that name an output format: -o is short
+// for --output-file, so "-o json" would otherwise write a file named json
+func checkOutputFile(path string) error {
+	kind, _, _ := strings.Cut(path, "=")
+	switch kind {
+	case format.OutputRaw, format.OutputJSON, format.OutputYAML, format.OutputTable, format.OutputTemplate:
+		return fmt.Errorf("-o is short for --output-file; use --output %s to choose an output format (or -o ./%s to write a file with that name)", path, path)
+	}
+	return nil
+}
+
+// addOutputFlag adds --output to a command that lists or shows stored data
+func addOutputFlag(cmd *cobra.Command) {
+	cmd.Flags().String("output", "", "Output format: raw, json, yaml, table or template=<go-template>")
+}
+
+// outputFromFlags reads --output, and --raw where the command has it,
+// exiting if the format is invalid
+func outputFromFlags(cmd *cobra.Command) format.Output {
+	output, _ := cmd.Flags().GetString("output")
+	raw, _ := cmd.Flags().GetBool("raw")
+	out, err := parseOutputFormat(output, raw)
+	if err != nil {
+		format.PrintError(err.Error())
+		os.Exit(1)
+	}
+	return out
+}
+
+// parseCompressed checks the encodings named by --compressed, returning them
+// as an Accept-Encoding value
+func parseCompressed(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	var encodings []string
+	for _, name := range strings.Split(value, ",") {
+		encoding, err := httpclient.ParseEncoding(name)
+		if err != nil {
+			return "", err
+		}
+		encodings = append(encodings, encoding)
+	}
+	return strings.Join(encodings, ", "), nil
+}
+
+// parseFilter parses a --filter expression; it returns nil if there is none
+func parseFilter(expr string) (*filter.Filter, error) {
+	if expr == "" {
+		return nil, nil
+	}
+	return filter.Parse(expr)
+}
+
+// filterResponse returns a copy of resp whose body holds the filter's results,
+// one JSON value per line
+func filterResponse(f *filter.Filter, resp *model.Response) (*model.Response, error) {
+	results, err := f.Apply(format.DecodeText(resp.ContentType, resp.Body))
+	if err != nil {
+		return nil, err
+	}
+	filtered := *resp
+	filtered.Body = ""
+	if len(results) > 0 {
+		filtered.Body = strings.Join(results, "\n") + "\n"
+	}
+	filtered.ContentType = "application/json"
+	// The wire size describes the whole body, not the filtered results
+	filtered.WireSize = 0
+	return &filtered, nil
+}
+
+// eventStreamOptions configures how server-sent events are shown as they
+// arrive. Raw output prints only each event's data; structured output prints
+// nothing until the stream closes.
+func eventStreamOptions(ctx context.Context, verbose bool, out format.Output) httpclient.StreamOptions {
+	return httpclient.StreamOptions{
+		Context:       ctx,
+		Force:         streamEvents,
+		LastEventID:   lastEventID,
+		Reconnect:     reconnectStream,
+		MaxTranscript: maxHistoryBodySize,
+		OnOpen: func(resp *model.Response) {
+			if out.IsDefault() {
+				format.PrintResponseHead(resp, verbose)
+			}
+		},
+		OnEvent: func(event model.Event) {
+			if out.IsRaw() {
+				fmt.Println(event.Data)
+			} else if out.IsDefault() {
+				format.PrintEvent(event)
+			}
+		},
+		OnReconnect: func(delay time.Duration, err error) {
+			reason := "stream closed by server"
+			if err != nil {
+				reason = fmt.Sprintf("stream failed: %v", err)
+			}
+			msg := fmt.Sprintf("%s; reconnecting in %s", reason, delay)
+			if out.IsDefault() {
+				format.PrintStreamNotice(msg)
+			} else {
+				fmt.Fprintln(os.Stderr, msg)
+			}
+		},
+	}
+}
+
+// redirectPolicy builds the redirect policy from the redirect flags
+func redirectPolicy() (httpclient.RedirectPolicy, error) {
+	auth, err := httpclient.ParseRedirectAuth(redirectAuth)
+	if err != nil {
+		return httpclient.RedirectPolicy{}, err
+	}
+	if maxRedirects < 0 {
+		return httpclient.RedirectPolicy{}, fmt.Errorf("--max-redirs must not be negative")
+	}
+
+	return httpclient.RedirectPolicy{
+		Follow:       followRedirects && !noFollow,
+		MaxRedirects: maxRedirects,
+		Auth:         auth,
+	}, nil
+}
+
+// requestProtocol returns the HTTP version selected by the protocol flags
+func requestProtocol() httpclient.Protocol {
+	switch {
+	case useHTTP1:
+		return httpclient.ProtocolHTTP1
+	case useHTTP2:
+		return httpclient.ProtocolHTTP2
+	case useH2C:
+		return httpclient.ProtocolH2C
+	}
+	return httpclient.ProtocolAuto
+}
+
+func parseHeaders(headerStrings []string) model.Headers {
+	result := make(model.Headers)
 	for _, h := range headerStrings {
 		parts := strings.SplitN(h, ":", 2)
 		if len(parts) == 2 {
 			key := strings.TrimSpace(parts[0])
 			value := strings.TrimSpace(parts[1])
-			result[key] = value
+			// Repeated -H flags for the same header send every value
+			result.Add(key, value)
 		}
 	}
 	return result
 }
 
-func saveToHistory(method, url string, headers map[string]string, body string, resp *model.Response) {
+// requestItems holds what was parsed from httpie-style request item arguments
+type requestItems struct {
+	headers model.Headers
+	query   []model.QueryParam
+	body    map[string]interface{} // nil when no data items were given
+}
+
+// parseRequestItems parses request items:
+//
+//	name=John                 string field in the JSON body
+//	age:=30                   raw JSON field
+//	tags[]=a                  append to an array
+//	user[address][city]=X     nested object field
+//	Header:Value              request header
+//	q==search                 URL query parameter
+func parseRequestItems(args []string) (*requestItems, error) {
+	items := &requestItems{headers: make(model.Headers)}
+
+	for _, arg := range args {
+		key, sep, value, ok := splitRequestItem(arg)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("%q (expected name=value, name:=json, Header:Value or name==value)", arg)
+		}
+
+		switch sep {
+		case ":":
+			items.headers.Add(strings.TrimSpace(key), strings.TrimSpace(value))
+		case "==":
+			items.query = append(items.query, model.QueryParam{Key: key, Value: value})
+		case "=", ":=":
+			var fieldValue interface{} = value
+			if sep == ":=" {
+				if err := json.Unmarshal([]byte(value), &fieldValue); err != nil {
+					return nil, fmt.Errorf("%q: invalid JSON value: %v", arg, err)
+				}
+			}
+
+			path, err := parseItemPath(key)
+			if err != nil {
+				return nil, fmt.Errorf("%q: %v", arg, err)
+			}
+
+			if items.body == nil {
+				items.body = make(map[string]interface{})
+			}
+			root, err := setItemValue(items.body, path, fieldValue)
+			if err != nil {
+				return nil, fmt.Errorf("%q: %v", arg, err)
+			}
+			items.body = root.(map[string]interface{})
+		}
+	}
+
+	return items, nil
+}
+
+// splitRequestItem splits an item at its first unescaped separator (":", "=", ":=" or "==").
+// A backslash escapes a separator character that is part of the key.
+func splitRequestItem(item string) (key, sep, value string, ok bool) {
+	var k strings.Builder
+	for i := 0; i < len(item); i++ {
+		c := item[i]
+		if c == '\\' && i+1 < len(item) {
+			k.WriteByte(item[i+1])
+			i++
+			continue
+		}
+		if c != ':' && c != '=' {
+			k.WriteByte(c)
+			continue
+		}
+
+		sep = string(c)
+		if i+1 < len(item) && item[i+1] == '=' {
+			sep += "="
+		}
+		return k.String(), sep, item[i+len(sep):], true
+	}
+	return "", "", "", false
+}
+
+// parseItemPath splits a key like user[address][city] or tags[] into path segments.
+// An empty segment means "append to array".
+func parseItemPath(key string) ([]string, error) {
+	idx := strings.Index(key, "[")
+	if idx == -1 {
+		return []string{key}, nil
+	}
+	if idx == 0 {
+		return nil, fmt.Errorf("missing field name before '['")
+	}
+
+	path := []string{key[:idx]}
+	rest := key[idx:]
+	for rest != "" {
+		if rest[0] != '[' {
+			return nil, fmt.Errorf("unexpected %q in field name", rest)
+		}
+		end := strings.Index(rest, "]")
+		if end == -1 {
+			return nil, fmt.Errorf("unclosed '[' in field name")
+		}
+		path = append(path, rest[1:end])
+		rest = rest[end+1:]
+	}
+	return path, nil
+}
+
+// setItemValue sets value at path inside container, creating nested objects and
+// arrays as needed, and returns the (possibly new) container
+func setItemValue(container interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	segment, rest := path[0], path[1:]
+
+	// Append to array: tags[]=a
+	if segment == "" {
+		arr, ok := container.([]interface{})
+		if container != nil && !ok {
+			return nil, fmt.Errorf("cannot append to non-array value")
+		}
+		child, err := setItemValue(nil, rest, value)
+		if err != nil {
+			return nil, err
+		}
+		return append(arr, child), nil
+	}
+
+	// Array index: items[0]=a (only where an array is or can be). An index may
+	// set an existing element or append the next one, but not leave gaps.
+	if index, err := strconv.Atoi(segment); err == nil && index >= 0 {
+		if arr, ok := container.([]interface{}); ok || container == nil {
+			if index > len(arr) {
+				return nil, fmt.Errorf("array index %d is out of range (next index is %d)", index, len(arr))
+			}
+			if index == len(arr) {
+				arr = append(arr, nil)
+			}
+			child, err := setItemValue(arr[index], rest, value)
+			if err != nil {
+				return nil, err
+			}
+			arr[index] = child
+			return arr, nil
+		}
+	}
+
+	// Object field: user[name]=a
+	obj, ok := container.(map[string]interface{})
+	if container != nil && !ok {
+		return nil, fmt.Errorf("cannot set field %q on non-object value", segment)
+	}
+	if obj == nil {
+		obj = make(map[string]interface{})
+	}
+	child, err := setItemValue(obj[segment], rest, value)
+	if err != nil {
+		return nil, err
+	}
+	obj[segment] = child
+	return obj, nil
+}
+
+// encodeItemsBody encodes the JSON body built from data items
+func encodeItemsBody(body map[string]interface{}) (string, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(body); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// appendQuery adds query parameters to rawURL. The existing query string is
+// kept exactly as typed, so signed URLs and valueless flags survive.
+func appendQuery(rawURL string, params []model.QueryParam) (string, error) {
+	if _, err := neturl.Parse(rawURL); err != nil {
+		return "", err
+	}
+	if len(params) == 0 {
+		return rawURL, nil
+	}
+
+	pairs := make([]string, 0, len(params))
+	for _, p := range params {
+		pairs = append(pairs, encodeQueryParam(p))
+	}
+
+	// The query goes before any fragment
+	base, fragment, hasFragment := strings.Cut(rawURL, "#")
+	switch {
+	case !strings.Contains(base, "?"):
+		base += "?"
+	case !strings.HasSuffix(base, "?") && !strings.HasSuffix(base, "&"):
+		base += "&"
+	}
+	base += strings.Join(pairs, "&")
+	if hasFragment {
+		base += "#" + fragment
+	}
+	return base, nil
+}
+
+// splitQuery decodes a raw query string into its parameters, keeping their
+// order. Malformed escapes are kept as they were typed.
+func splitQuery(rawQuery string) []model.QueryParam {
+	var params []model.QueryParam
+	for _, pair := range strings.Split(rawQuery, "&") {
+		if pair == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(pair, "=")
+		if k, err := neturl.QueryUnescape(key); err == nil {
+			key = k
+		}
+		if v, err := neturl.QueryUnescape(value); err == nil {
+			value = v
+		}
+		params = append(params, model.QueryParam{Key: key, Value: value})
+	}
+	return params
+}
+
+// encodeQueryParam URL-encodes a single key=value pair
+func encodeQueryParam(p model.QueryParam) string {
+	return neturl.QueryEscape(p.Key) + "=" + neturl.QueryEscape(p.Value)
+}
+
+// parseQueryFlags parses -q key=value flags into query parameters
+func parseQueryFlags(values []string) ([]model.QueryParam, error) {
+	params := make([]model.QueryParam, 0, len(values))
+	for _, v := range values {
+		key, value, _ := strings.Cut(v, "=")
+		if key == "" {
+			return nil, fmt.Errorf("invalid query parameter %q (expected key=value)", v)
+		}
+		params = append(params, model.QueryParam{Key: key, Value: value})
+	}
+	return params, nil
+}
+
+// withDefaultQuery prepends default parameters whose keys aren't already set,
+// either in rawURL's query or in params
+func withDefaultQuery(rawURL string, defaults, params []model.QueryParam) []model.QueryParam {
+	if len(defaults) == 0 {
+		return params
+	}
+
+	set := make(map[string]bool)
+	if u, err := neturl.Parse(rawURL); err == nil {
+		for _, p := range splitQuery(u.RawQuery) {
+			set[p.Key] = true
+		}
+	}
+	for _, p := range params {
+		set[p.Key] = true
+	}
+
+	var merged []model.QueryParam
+	for _, d := range defaults {
+		if !set[d.Key] {
+			merged = append(merged, d)
+		}
+	}
+	return append(merged, params...)
+}
+
+// resolveQuery substitutes {{env:NAME}} and {{secret:name}} references in
+// query values. Only the returned copy holds the resolved values.
+func resolveQuery(params []model.QueryParam) ([]model.QueryParam, error) {
+	resolved := make([]model.QueryParam, len(params))
+	for i, p := range params {
+		value, err := expandEnvRefs(p.Value)
+		if err != nil {
+			return nil, err
+		}
+		if _, value, err = resolveSecrets(nil, value); err != nil {
+			return nil, err
+		}
+		resolved[i] = model.QueryParam{Key: p.Key, Value: value}
+	}
+	return resolved, nil
+}
+
+// envRefPattern matches {{env:NAME}} references to environment variables
+var envRefPattern = regexp.MustCompile(`\{\{\s*env:([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// expandEnvRefs replaces {{env:NAME}} references with environment variable values
+func expandEnvRefs(s string) (string, error) {
+	var missing string
+	expanded := envRefPattern.ReplaceAllStringFunc(s, func(ref string) string {
+		name := envRefPattern.FindStringSubmatch(ref)[1]
+		value, ok := os.LookupEnv(name)
+		if !ok && missing == "" {
+			missing = name
+		}
+		return value
+	})
+	if missing != "" {
+		return "", fmt.Errorf("environment variable %s is not set", missing)
+	}
+	return expanded, nil
+}
+
+// prepareBodyContentType sets a detected Content-Type when none was given and
+// validates JSON bodies. A body that only looks like JSON is validated when
+// its type is being detected, never when the user chose another type.
+func prepareBodyContentType(headers model.Headers, body, filename string) error {
+	ct := headers.Get("Content-Type")
+	isJSON := httpclient.IsJSONContentType(ct)
+	if ct == "" {
+		ct = httpclient.DetectContentType([]byte(body), filename)
+		headers.Set("Content-Type", ct)
+		isJSON = httpclient.IsJSONContentType(ct) || httpclient.LooksLikeJSON([]byte(body))
+	}
+
+	if isJSON {
+		if err := httpclient.ValidateJSON([]byte(body)); err != nil {
+			if filename != "" {
+				return fmt.Errorf("%s: %v", filename, err)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// streamedUpload is a request body streamed from a file or stdin. It hashes the
+// data as it is sent so history can record a summary instead of the contents.
+type streamedUpload struct {
+	name  string
+	file  *os.File
+	size  int64 // -1 when unknown (stdin)
+	hash  hash.Hash
+	count int64
+}
+
+// openUpload opens a file (or "-" for stdin) for streaming, applying the same
+// path checks as @file bodies
+func openUpload(path string) (*streamedUpload, error) {
+	if path == "-" {
+		return &streamedUpload{name: "stdin", file: os.Stdin, size: -1, hash: sha256.New()}, nil
+	}
+
+	realPath, err := validateFilePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(realPath)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if info.IsDir() {
+		f.Close()
+		return nil, fmt.Errorf("%s is a directory", path)
+	}
+
+	return &streamedUpload{name: path, file: f, size: info.Size(), hash: sha256.New()}, nil
+}
+
+// Body returns the streaming request body. Files are sent with a known
+// Content-Length; stdin uses chunked transfer encoding.
+func (u *streamedUpload) Body() *httpclient.Body {
+	contentType := "application/octet-stream"
+	if u.size >= 0 {
+		if byExt := mime.TypeByExtension(filepath.Ext(u.name)); byExt != "" {
+			contentType = byExt
+		}
+	}
+
+	reader := format.NewProgressReader(io.TeeReader(u.file, u), "Uploading", u.size)
+	return &httpclient.Body{
+		Reader:        readCloser{reader, u.file},
+		ContentType:   contentType,
+		ContentLength: u.size,
+	}
+}
+
+// Write hashes and counts the bytes read from the upload
+func (u *streamedUpload) Write(p []byte) (int, error) {
+	u.count += int64(len(p))
+	return u.hash.Write(p)
+}
+
+// Summary describes the uploaded data for history
+func (u *streamedUpload) Summary() string {
+	return fmt.Sprintf("[uploaded %s: %d bytes, sha256:%x]", u.name, u.count, u.hash.Sum(nil))
+}
+
+// readCloser pairs a (possibly wrapped) reader with the file it reads from
+type readCloser struct {
+	io.Reader
+	closer io.Closer
+}
+
+// Close finishes any progress display and closes the underlying file
+func (rc readCloser) Close() error {
+	if c, ok := rc.Reader.(io.Closer); ok {
+		c.Close()
+	}
+	return rc.closer.Close()
+}
+
+// summarizeLargeBody replaces bodies too large to keep in history with their size and hash
+func summarizeLargeBody(body string) string {
+	if len(body) <= maxHistoryBodySize {
+		return body
+	}
+	return fmt.Sprintf("[body omitted: %d bytes, sha256:%x]", len(body), sha256.Sum256([]byte(body)))
+}
+
+func saveToHistory(method, url string, headers model.Headers, body string, resp *model.Response) {
 	store, err := storage.NewStorage()
 	if err != nil {
 		// Silently fail - don't interrupt the user
@@ -194,15 +1127,20 @@ func saveToHistory(method, url string, headers map[string]string, body string, r
 	// Filter sensitive headers before storing
 	filteredHeaders := filterSensitiveHeaders(headers)
 
-	// Filter sensitive response headers if present
+	// Filter sensitive response headers if present, and scrub any echoed secrets
 	var filteredResp *model.Response
 	if resp != nil {
 		filteredResp = &model.Response{
-			StatusCode: resp.StatusCode,
-			Status:     resp.Status,
-			Headers:    filterSensitiveHeaders(resp.Headers),
-			Body:       resp.Body,
-			DurationMs: resp.DurationMs,
+			StatusCode:  resp.StatusCode,
+			Status:      resp.Status,
+			Protocol:    resp.Protocol,
+			Headers:     scrubHeaders(filterSensitiveHeaders(resp.Headers)),
+			Body:        scrubSecrets(resp.Body),
+			ContentType: resp.ContentType,
+			WireSize:    resp.WireSize,
+			DurationMs:  resp.DurationMs,
+			Download:    resp.Download,
+			Redirects:   scrubRedirects(resp.Redirects),
 		}
 	}
 
@@ -212,14 +1150,29 @@ func saveToHistory(method, url string, headers map[string]string, body string, r
 		Method:    method,
 		URL:       url,
 		Headers:   filteredHeaders,
-		Body:      body,
+		Body:      summarizeLargeBody(body),
 		Response:  filteredResp,
 	}
 
 	_ = store.AddToHistory(req)
 }
 
-func saveRequestToCollection(collectionName, method, url string, headers map[string]string, body string) {
+// scrubRedirects returns a copy of redirects with echoed secrets removed from
+// each hop's URL and Location
+func scrubRedirects(redirects []model.Redirect) []model.Redirect {
+	if len(redirects) == 0 {
+		return redirects
+	}
+	scrubbed := make([]model.Redirect, len(redirects))
+	for i, r := range redirects {
+		r.URL = scrubSecrets(r.URL)
+		r.Location = scrubSecrets(r.Location)
+		scrubbed[i] = r
+	}
+	return scrubbed
+}
+
+func saveRequestToCollection(collectionName, method, url string, query []model.QueryParam, headers model.Headers, body string) {
 	store, err := storage.NewStorage()
 	if err != nil {
 		format.PrintError(fmt.Sprintf("Failed to save to collection: %v", err))
@@ -230,6 +1183,7 @@ func saveRequestToCollection(collectionName, method, url string, headers map[str
 		Name:    "",
 		Method:  method,
 		URL:     url,
+		Query:   query,
 		Headers: headers,
 		Body:    body,
 	}
@@ -244,11 +1198,12 @@ func saveRequestToCollection(collectionName, method, url string, headers map[str
 
 // resolveAlias resolves URL aliases to their full URLs.
 // If the URL starts with http:// or https://, it's returned as-is.
-// Otherwise, it checks if the first path segment is a known alias.
-func resolveAlias(url string) string {
+// Otherwise, it checks if the first path segment is a known alias and also
+// returns that alias's default query parameters.
+func resolveAlias(url string) (string, []model.QueryParam) {
 	// Skip if already a full URL
 	if strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://") {
-		return url
+		return url, nil
 	}
 
 	// Split on first / to get potential alias name and path
@@ -266,27 +1221,44 @@ func resolveAlias(url string) string {
 	store, err := storage.NewStorage()
 	if err != nil {
 		// Storage error, return URL as-is
-		return url
+		return url, nil
 	}
 
 	baseURL, exists, err := store.GetAlias(aliasName)
 	if err != nil || !exists {
 		// Alias not found or error, return URL as-is
-		return url
+		return url, nil
 	}
+	query, _ := store.GetAliasQuery(aliasName)
 
 	// Combine base URL with path (auto-normalize trailing slashes)
 	baseURL = strings.TrimSuffix(baseURL, "/")
 	path = strings.TrimPrefix(path, "/")
 
 	if path == "" {
-		return baseURL
+		return baseURL, query
 	}
-	return baseURL + "/" + path
+	return baseURL + "/" + path, query
 }
 
 // readBodyFromFile reads file content with path validation to prevent directory traversal
 func readBodyFromFile(filename string) (string, error) {
+	realPath, err := validateFilePath(filename)
+	if err != nil {
+		return "", err
+	}
+
+	content, err := os.ReadFile(realPath)
+	if err != nil {
+		return "", err
+	}
+
+	return string(content), nil
+}
+
+// validateFilePath resolves filename and ensures it (and any symlink target)
+// stays within the working directory to prevent directory traversal
+func validateFilePath(filename string) (string, error) {
 	// Get working directory
 	wd, err := os.Getwd()
 	if err != nil {
@@ -310,7 +1282,7 @@ func readBodyFromFile(filename string) (string, error) {
 	// Check for symlinks - resolve and verify target is also within working directory
 	realPath, err := filepath.EvalSymlinks(cleanPath)
 	if err != nil {
-		// If file doesn't exist, we'll let ReadFile handle the error
+		// If file doesn't exist, we'll let the caller's open handle the error
 		if !os.IsNotExist(err) {
 			return "", fmt.Errorf("failed to resolve path: %w", err)
 		}
@@ -322,26 +1294,114 @@ func readBodyFromFile(filename string) (string, error) {
 		}
 	}
 
-	content, err := os.ReadFile(realPath)
+	return realPath, nil
+}
+
+// parseFormField parses a -F argument: name=value, name=@file or name=@file;type=mime
+func parseFormField(arg string) (httpclient.FormField, error) {
+	name, value, ok := strings.Cut(arg, "=")
+	if !ok || name == "" {
+		return httpclient.FormField{}, fmt.Errorf("invalid form field %q (expected name=value)", arg)
+	}
+
+	field := httpclient.FormField{Name: name}
+	if !strings.HasPrefix(value, "@") {
+		field.Value = value
+		return field, nil
+	}
+
+	// File upload, with an optional ;type= parameter overriding the MIME type
+	path := strings.TrimPrefix(value, "@")
+	if idx := strings.Index(path, ";type="); idx != -1 {
+		field.ContentType = path[idx+len(";type="):]
+		path = path[:idx]
+	}
+
+	realPath, err := validateFilePath(path)
 	if err != nil {
-		return "", err
+		return httpclient.FormField{}, err
 	}
+	field.File = realPath
 
-	return string(content), nil
+	return field, nil
+}
+
+// parseFormFields parses all -F arguments, resolving secret references in values
+func parseFormFields(args []string, resolve bool) ([]httpclient.FormField, error) {
+	fields := make([]httpclient.FormField, 0, len(args))
+	for _, arg := range args {
+		field, err := parseFormField(arg)
+		if err != nil {
+			return nil, err
+		}
+		if resolve {
+			if _, field.Value, err = resolveSecrets(nil, field.Value); err != nil {
+				return nil, err
+			}
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// buildFormBody builds a multipart or URL-encoded body from -F arguments
+func buildFormBody(args []string, urlEncoded bool) (*httpclient.Body, error) {
+	fields, err := parseFormFields(args, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if urlEncoded {
+		return httpclient.NewURLEncodedBody(fields)
+	}
+	return httpclient.NewMultipartBody(fields)
+}
+
+// saveFormToCollection saves a URL-encoded form request to a collection.
+// Multipart requests reference local files and can't be replayed, so they are skipped.
+func saveFormToCollection(collectionName, method, url string, query []model.QueryParam, headers model.Headers) {
+	if !formURLEncoded {
+		format.PrintError("Multipart form requests can't be saved to a collection")
+		return
+	}
+
+	fields, err := parseFormFields(formFields, false)
+	if err != nil {
+		format.PrintError(fmt.Sprintf("Failed to save to collection: %v", err))
+		return
+	}
+
+	formBody, err := httpclient.NewURLEncodedBody(fields)
+	if err != nil {
+		format.PrintError(fmt.Sprintf("Failed to save to collection: %v", err))
+		return
+	}
+	encoded, _ := io.ReadAll(formBody.Reader)
+
+	saved := headers.Clone()
+	if !saved.Has("Content-Type") {
+		saved.Set("Content-Type", formBody.ContentType)
+	}
+
+	saveRequestToCollection(collectionName, method, url, query, saved, string(encoded))
 }
 
 // filterSensitiveHeaders returns a copy of headers with sensitive values redacted
-func filterSensitiveHeaders(headers map[string]string) map[string]string {
+func filterSensitiveHeaders(headers model.Headers) model.Headers {
 	if headers == nil {
 		return nil
 	}
 
-	filtered := make(map[string]string)
-	for k, v := range headers {
-		if sensitiveHeaders[strings.ToLower(k)] {
-			filtered[k] = "[REDACTED]"
-		} else {
-			filtered[k] = v
+	filtered := make(model.Headers, len(headers))
+	for k, values := range headers {
+		sensitive := sensitiveHeaders[strings.ToLower(k)]
+		for _, v := range values {
+			// Secret references carry no credential themselves, so keep them runnable
+			if sensitive && !isSecretTemplate(v) {
+				filtered[k] = append(filtered[k], "[REDACTED]")
+			} else {
+				filtered[k] = append(filtered[k], v)
+			}
 		}
 	}
 	return filtered
@@ -364,7 +1424,8 @@ func warnIfSensitiveBody(body string) {
 		return
 	}
 
-	lowerBody := strings.ToLower(body)
+	// Secret references are never stored resolved, so they don't count
+	lowerBody := strings.ToLower(secretRefPattern.ReplaceAllString(body, ""))
 	for _, pattern := range sensitiveBodyPatterns {
 		if strings.Contains(lowerBody, pattern) {
 			fmt.Fprintln(os.Stderr, "WARNING: Request body may contain sensitive data (e.g., passwords, tokens). This will be stored in history.")
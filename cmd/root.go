@@ -4,6 +4,7 @@ import (
 	"os"
 
 	"github.com/spf13/cobra"
+	"api/internal/format"
 )
 
 var rootCmd = &cobra.Command{
@@ -18,6 +19,15 @@ Examples:
   apicli post https://api.example.com/users -d '{"name": "John"}'
   apicli history
   apicli collection list`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		colorMode, _ := cmd.Flags().GetString("color")
+		if err := format.SetColorMode(colorMode); err != nil {
+			format.PrintError(err.Error())
+			os.Exit(1)
+		}
+		noPager, _ := cmd.Flags().GetBool("no-pager")
+		format.PagerEnabled = !noPager
+	},
 }
 
 // Execute runs the root command
@@ -30,4 +40,6 @@ func Execute() {
 func init() {
 	// Global flags can be added here
 	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "Show response headers")
+	rootCmd.PersistentFlags().String("color", "auto", "Color output: always, never or auto (only on a terminal, and not when NO_COLOR is set)")
+	rootCmd.PersistentFlags().Bool("no-pager", false, "Don't page long responses through $PAGER")
 }
@@ -0,0 +1,353 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"api/internal/format"
+	"api/internal/model"
+	"api/internal/storage"
+)
+
+// passphraseEnvVar lets scripts supply the vault passphrase without a prompt
+const passphraseEnvVar = "APICLI_PASSPHRASE"
+
+var (
+	// secretRefPattern matches {{secret:name}} references in headers and bodies
+	secretRefPattern = regexp.MustCompile(`\{\{\s*secret:([A-Za-z0-9_.-]+)\s*\}\}`)
+
+	// secretNamePattern restricts secret names to characters usable in references
+	secretNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+	// authSchemePattern matches what may remain of a header value once its secret
+	// references are removed (e.g. "Bearer" from "Bearer {{secret:token}}")
+	authSchemePattern = regexp.MustCompile(`^[A-Za-z-]*$`)
+
+	// openedVault caches the decrypted vault so the passphrase is asked only once
+	openedVault *storage.SecretVault
+
+	// resolvedSecrets tracks values substituted into requests so they can be
+	// scrubbed from anything echoed back before it reaches history
+	resolvedSecrets = map[string]bool{}
+)
+
+// minScrubLength avoids redacting short values that would match ordinary text
+const minScrubLength = 4
+
+func init() {
+	secretCmd := &cobra.Command{
+		Use:   "secret",
+		Short: "Manage encrypted secrets",
+		Long: `Manage secrets stored in an encrypted vault (~/.apicli/secrets.enc).
+
+Secrets are encrypted with AES-GCM using a key derived from your passphrase.
+Reference them in headers and bodies as {{secret:name}}; they are resolved
+only when the request is sent and are never written to history.
+
+The passphrase is read from $` + passphraseEnvVar + ` or prompted for.
+
+Example:
+  apicli secret set github-token
+  apicli get https://api.github.com/user -H "Authorization: Bearer {{secret:github-token}}"`,
+	}
+
+	setCmd := &cobra.Command{
+		Use:   "set <name> [value]",
+		Short: "Store a secret (prompts for the value if omitted)",
+		Args:  cobra.RangeArgs(1, 2),
+		Run:   runSecretSet,
+	}
+
+	getCmd := &cobra.Command{
+		Use:   "get <name>",
+		Short: "Print a secret value",
+		Args:  cobra.ExactArgs(1),
+		Run:   runSecretGet,
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List secret names",
+		Run:   runSecretList,
+	}
+
+	rmCmd := &cobra.Command{
+		Use:     "rm <name>",
+		Aliases: []string{"delete"},
+		Short:   "Remove a secret",
+		Args:    cobra.ExactArgs(1),
+		Run:     runSecretRm,
+	}
+
+	secretCmd.AddCommand(setCmd, getCmd, listCmd, rmCmd)
+	rootCmd.AddCommand(secretCmd)
+}
+
+func runSecretSet(cmd *cobra.Command, args []string) {
+	name := args[0]
+	if !secretNamePattern.MatchString(name) {
+		format.PrintError("Secret names may only contain letters, digits, '.', '_' and '-'")
+		os.Exit(1)
+	}
+
+	vault, err := loadSecretVault(true)
+	if err != nil {
+		format.PrintError(fmt.Sprintf("Failed to open secrets: %v", err))
+		os.Exit(1)
+	}
+
+	var value string
+	if len(args) == 2 {
+		value = args[1]
+	} else {
+		value, err = readSecretValue(fmt.Sprintf("Value for '%s': ", name))
+		if err != nil {
+			format.PrintError(fmt.Sprintf("Failed to read secret value: %v", err))
+			os.Exit(1)
+		}
+	}
+
+	vault.Set(name, value)
+	if err := vault.Save(); err != nil {
+		format.PrintError(fmt.Sprintf("Failed to save secret: %v", err))
+		os.Exit(1)
+	}
+
+	format.PrintSuccess(fmt.Sprintf("Secret '%s' saved", name))
+}
+
+func runSecretGet(cmd *cobra.Command, args []string) {
+	name := args[0]
+
+	vault, err := loadSecretVault(false)
+	if err != nil {
+		format.PrintError(fmt.Sprintf("Failed to open secrets: %v", err))
+		os.Exit(1)
+	}
+
+	value, exists := vault.Get(name)
+	if !exists {
+		format.PrintError(fmt.Sprintf("Secret '%s' not found", name))
+		os.Exit(1)
+	}
+
+	fmt.Println(value)
+}
+
+func runSecretList(cmd *cobra.Command, args []string) {
+	exists, err := storage.SecretVaultExists()
+	if err != nil {
+		format.PrintError(fmt.Sprintf("Failed to open secrets: %v", err))
+		os.Exit(1)
+	}
+	if !exists {
+		format.PrintSecretList(nil)
+		return
+	}
+
+	vault, err := loadSecretVault(false)
+	if err != nil {
+		format.PrintError(fmt.Sprintf("Failed to open secrets: %v", err))
+		os.Exit(1)
+	}
+
+	format.PrintSecretList(vault.Names())
+}
+
+func runSecretRm(cmd *cobra.Command, args []string) {
+	name := args[0]
+
+	vault, err := loadSecretVault(false)
+	if err != nil {
+		format.PrintError(fmt.Sprintf("Failed to open secrets: %v", err))
+		os.Exit(1)
+	}
+
+	if !vault.Delete(name) {
+		format.PrintError(fmt.Sprintf("Secret '%s' not found", name))
+		os.Exit(1)
+	}
+
+	if err := vault.Save(); err != nil {
+		format.PrintError(fmt.Sprintf("Failed to remove secret: %v", err))
+		os.Exit(1)
+	}
+
+	format.PrintSuccess(fmt.Sprintf("Secret '%s' removed", name))
+}
+
+// loadSecretVault opens the vault, asking for the passphrase if needed.
+// When create is false, a missing vault is reported as an error.
+func loadSecretVault(create bool) (*storage.SecretVault, error) {
+	if openedVault != nil {
+		return openedVault, nil
+	}
+
+	exists, err := storage.SecretVaultExists()
+	if err != nil {
+		return nil, err
+	}
+	if !exists && !create {
+		return nil, fmt.Errorf("no secrets stored yet (use 'apicli secret set')")
+	}
+
+	passphrase := os.Getenv(passphraseEnvVar)
+	if passphrase == "" {
+		passphrase, err = promptPassword("Vault passphrase: ")
+		if err != nil {
+			return nil, err
+		}
+
+		// Confirm the passphrase when creating a new vault to avoid typos locking it
+		if !exists {
+			confirm, err := promptPassword("Confirm passphrase: ")
+			if err != nil {
+				return nil, err
+			}
+			if confirm != passphrase {
+				return nil, fmt.Errorf("passphrases do not match")
+			}
+		}
+	}
+
+	vault, err := storage.OpenSecretVault(passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	openedVault = vault
+	return vault, nil
+}
+
+// promptPassword reads a line from the terminal without echoing it
+func promptPassword(prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", fmt.Errorf("no terminal available to prompt for passphrase (set $%s)", passphraseEnvVar)
+	}
+
+	fmt.Fprint(os.Stderr, prompt)
+	value, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	return string(value), nil
+}
+
+// readSecretValue prompts for a secret value on a terminal, or reads it from piped stdin
+func readSecretValue(prompt string) (string, error) {
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		return promptPassword(prompt)
+	}
+
+	value, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	return strings.TrimRight(value, "\r\n"), nil
+}
+
+// hasSecretRefs reports whether headers or body contain {{secret:name}} references
+func hasSecretRefs(headers model.Headers, body string) bool {
+	if secretRefPattern.MatchString(body) {
+		return true
+	}
+	for _, values := range headers {
+		for _, v := range values {
+			if secretRefPattern.MatchString(v) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// resolveSecrets returns copies of headers and body with {{secret:name}} references
+// replaced by their values. The originals are left untouched so that only the
+// unresolved references are ever persisted.
+func resolveSecrets(headers model.Headers, body string) (model.Headers, string, error) {
+	if !hasSecretRefs(headers, body) {
+		return headers, body, nil
+	}
+
+	vault, err := loadSecretVault(false)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var missing []string
+	replace := func(s string) string {
+		return secretRefPattern.ReplaceAllStringFunc(s, func(ref string) string {
+			name := secretRefPattern.FindStringSubmatch(ref)[1]
+			value, exists := vault.Get(name)
+			if !exists {
+				missing = append(missing, name)
+				return ref
+			}
+			resolvedSecrets[value] = true
+			return value
+		})
+	}
+
+	resolvedHeaders := make(model.Headers, len(headers))
+	for k, values := range headers {
+		for _, v := range values {
+			resolvedHeaders[k] = append(resolvedHeaders[k], replace(v))
+		}
+	}
+	resolvedBody := replace(body)
+
+	if len(missing) > 0 {
+		return nil, "", fmt.Errorf("secret '%s' not found", missing[0])
+	}
+
+	return resolvedHeaders, resolvedBody, nil
+}
+
+// isSecretTemplate reports whether a header value only carries secret references
+// (optionally preceded by an auth scheme), so it is safe to store unredacted
+func isSecretTemplate(value string) bool {
+	if !secretRefPattern.MatchString(value) {
+		return false
+	}
+	rest := strings.TrimSpace(secretRefPattern.ReplaceAllString(value, ""))
+	return authSchemePattern.MatchString(rest)
+}
+
+// scrubSecrets redacts any secret values resolved during this run from s.
+// Longer values go first, so a secret containing another is redacted whole.
+func scrubSecrets(s string) string {
+	values := make([]string, 0, len(resolvedSecrets))
+	for value := range resolvedSecrets {
+		if len(value) >= minScrubLength {
+			values = append(values, value)
+		}
+	}
+	sort.Slice(values, func(i, j int) bool { return len(values[i]) > len(values[j]) })
+	for _, value := range values {
+		s = strings.ReplaceAll(s, value, "[REDACTED]")
+	}
+	return s
+}
+
+// scrubHeaders redacts resolved secret values from every header value
+func scrubHeaders(headers model.Headers) model.Headers {
+	if headers == nil {
+		return nil
+	}
+	scrubbed := make(model.Headers, len(headers))
+	for k, values := range headers {
+		for _, v := range values {
+			scrubbed[k] = append(scrubbed[k], scrubSecrets(v))
+		}
+	}
+	return scrubbed
+}
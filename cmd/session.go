@@ -0,0 +1,203 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"api/internal/format"
+	httpclient "api/internal/http"
+	"api/internal/model"
+	"api/internal/storage"
+)
+
+var (
+	// sessionName selects the named session whose cookies a request uses and updates
+	sessionName string
+
+	// sessionNamePattern restricts session names to simple identifiers
+	sessionNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+	// showCookieValues prints cookie values in 'session show'
+	showCookieValues bool
+)
+
+func init() {
+	sessionCmd := &cobra.Command{
+		Use:   "session",
+		Short: "Manage cookie sessions",
+		Long: `Manage named sessions that carry cookies between requests.
+
+Pass --session <name> to a request to send the cookies saved in that session
+and save any cookies the server sets. Sessions are created on first use.
+Cookie values are stored in ~/.apicli/apicli.db and are never written to history.
+
+Example:
+  apicli post https://api.example.com/login --session work -d @login.json
+  apicli get https://api.example.com/me --session work`,
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List sessions",
+		Run:   runSessionList,
+	}
+
+	showCmd := &cobra.Command{
+		Use:   "show <name>",
+		Short: "Show the cookies in a session",
+		Args:  cobra.ExactArgs(1),
+		Run:   runSessionShow,
+	}
+	showCmd.Flags().BoolVar(&showCookieValues, "values", false, "Show cookie values")
+
+	clearCmd := &cobra.Command{
+		Use:   "clear <name>",
+		Short: "Delete a session and its cookies",
+		Args:  cobra.ExactArgs(1),
+		Run:   runSessionClear,
+	}
+
+	sessionCmd.AddCommand(listCmd, showCmd, clearCmd)
+	rootCmd.AddCommand(sessionCmd)
+}
+
+func runSessionList(cmd *cobra.Command, args []string) {
+	store, err := storage.NewStorage()
+	if err != nil {
+		format.PrintError(fmt.Sprintf("Failed to load sessions: %v", err))
+		os.Exit(1)
+	}
+
+	sessions, err := store.LoadSessions()
+	if err != nil {
+		format.PrintError(fmt.Sprintf("Failed to load sessions: %v", err))
+		os.Exit(1)
+	}
+
+	format.PrintSessionList(sessions)
+}
+
+func runSessionShow(cmd *cobra.Command, args []string) {
+	name := args[0]
+
+	store, err := storage.NewStorage()
+	if err != nil {
+		format.PrintError(fmt.Sprintf("Failed to load session: %v", err))
+		os.Exit(1)
+	}
+
+	session, err := store.GetSession(name)
+	if err != nil {
+		format.PrintError(fmt.Sprintf("Failed to load session: %v", err))
+		os.Exit(1)
+	}
+
+	if session == nil {
+		format.PrintError(fmt.Sprintf("Session '%s' not found", name))
+		os.Exit(1)
+	}
+
+	format.PrintSession(session, showCookieValues)
+}
+
+func runSessionClear(cmd *cobra.Command, args []string) {
+	name := args[0]
+
+	store, err := storage.NewStorage()
+	if err != nil {
+		format.PrintError(fmt.Sprintf("Failed to clear session: %v", err))
+		os.Exit(1)
+	}
+
+	existed, err := store.DeleteSession(name)
+	if err != nil {
+		format.PrintError(fmt.Sprintf("Failed to clear session: %v", err))
+		os.Exit(1)
+	}
+
+	if !existed {
+		format.PrintError(fmt.Sprintf("Session '%s' not found", name))
+		os.Exit(1)
+	}
+
+	format.PrintSuccess(fmt.Sprintf("Session '%s' cleared", name))
+}
+
+// loadSessionJar returns a cookie jar holding the cookies saved in the named
+// session, or an empty jar if the session doesn't exist yet
+func loadSessionJar(name string) (*httpclient.SessionJar, error) {
+	if !sessionNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("session names may only contain letters, digits, '.', '_' and '-'")
+	}
+
+	store, err := storage.NewStorage()
+	if err != nil {
+		return nil, err
+	}
+	defer store.Close()
+
+	session, err := store.GetSession(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var cookies []model.Cookie
+	if session != nil {
+		cookies = session.Cookies
+	}
+	return httpclient.NewSessionJar(cookies)
+}
+
+// saveSessionJar persists the cookies in jar to the named session
+func saveSessionJar(name string, jar *httpclient.SessionJar) error {
+	store, err := storage.NewStorage()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	return store.SaveSession(model.Session{
+		Name:      name,
+		UpdatedAt: time.Now(),
+		Cookies:   jar.Saved(),
+	})
+}
+
+// minCookieScrubLength keeps short cookie values such as "1" or "dark" from
+// being redacted wherever they appear
+const minCookieScrubLength = 8
+
+// credentialCookieNames are name fragments of cookies that usually hold credentials
+var credentialCookieNames = []string{"sess", "sid", "auth", "token", "jwt", "login", "remember", "csrf", "xsrf"}
+
+// redactSessionCookies marks the values of credential-like cookies in jar for
+// scrubbing, so that servers echoing them back don't leak them into history
+func redactSessionCookies(jar *httpclient.SessionJar) {
+	for _, c := range jar.Saved() {
+		if isCredentialCookie(c) {
+			resolvedSecrets[c.Value] = true
+		}
+	}
+}
+
+// isCredentialCookie reports whether a cookie looks like it carries a
+// credential: long enough, and either HttpOnly or named like a session or token
+func isCredentialCookie(c model.Cookie) bool {
+	if len(c.Value) < minCookieScrubLength {
+		return false
+	}
+	if c.HttpOnly {
+		return true
+	}
+	name := strings.ToLower(c.Name)
+	for _, fragment := range credentialCookieNames {
+		if strings.Contains(name, fragment) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,229 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"api/internal/format"
+	httpclient "api/internal/http"
+)
+
+var (
+	// wsScriptFile holds messages to send, one per line ("-" for stdin)
+	wsScriptFile string
+
+	// wsWait is how long scripted mode waits for replies after the last message
+	wsWait time.Duration
+)
+
+// maxWebSocketMessage bounds a single line of input sent as a message
+const maxWebSocketMessage = 1024 * 1024
+
+func init() {
+	wsCmd := &cobra.Command{
+		Use:   "ws <url>",
+		Short: "Open a WebSocket connection",
+		Long: `Open a WebSocket connection and exchange text messages.
+
+Interactively, each line you type is sent as a message and messages from the
+server are printed as they arrive; press Ctrl-D or Ctrl-C to close. With
+--file, or when stdin is not a terminal, each line of input is sent in turn
+and the connection is closed after waiting --wait for replies.
+
+http(s) URLs and aliases are converted to ws(s). JSON messages are pretty-printed.
+
+Example:
+  apicli ws wss://echo.example.com/socket -H "Authorization: Bearer {{secret:token}}"
+  apicli ws myapi/events --file messages.txt
+  echo '{"type":"ping"}' | apicli ws wss://echo.example.com/socket`,
+		Args: cobra.ExactArgs(1),
+		Run:  runWebSocket,
+	}
+	wsCmd.Flags().StringArrayVarP(&headers, "header", "H", []string{}, "Add header to the handshake (can be used multiple times)")
+	wsCmd.Flags().StringVarP(&wsScriptFile, "file", "f", "", "Send each line of a file (or - for stdin) as a message, then close")
+	wsCmd.Flags().DurationVar(&wsWait, "wait", 2*time.Second, "In scripted mode, how long to wait for replies after the last message")
+	wsCmd.Flags().StringVar(&sessionName, "session", "", "Send and save cookies using the named session")
+	wsCmd.Flags().BoolVar(&noHistory, "no-history", false, "Don't save to history")
+
+	rootCmd.AddCommand(wsCmd)
+}
+
+func runWebSocket(cmd *cobra.Command, args []string) {
+	verbose, _ := cmd.Flags().GetBool("verbose")
+	url, aliasQuery := resolveAlias(args[0])
+
+	// Alias default query parameters apply as for any other request
+	query := withDefaultQuery(url, aliasQuery, nil)
+	fullURL, err := appendQuery(url, query)
+	if err != nil {
+		format.PrintError(fmt.Sprintf("Invalid URL: %v", err))
+		os.Exit(1)
+	}
+	sendQuery, err := resolveQuery(query)
+	if err != nil {
+		format.PrintError(fmt.Sprintf("Failed to resolve query parameters: %v", err))
+		os.Exit(1)
+	}
+	sendURL, err := appendQuery(url, sendQuery)
+	if err != nil {
+		format.PrintError(fmt.Sprintf("Invalid URL: %v", err))
+		os.Exit(1)
+	}
+
+	wsURL, err := httpclient.WebSocketURL(fullURL)
+	if err != nil {
+		format.PrintError(err.Error())
+		os.Exit(1)
+	}
+	sendWSURL, err := httpclient.WebSocketURL(sendURL)
+	if err != nil {
+		format.PrintError(err.Error())
+		os.Exit(1)
+	}
+
+	headerMap := parseHeaders(headers)
+	sendHeaders, _, err := resolveSecrets(headerMap, "")
+	if err != nil {
+		format.PrintError(fmt.Sprintf("Failed to resolve secrets: %v", err))
+		os.Exit(1)
+	}
+
+	// Pick the message source before connecting so a bad file fails fast
+	var input io.Reader = os.Stdin
+	scripted := wsScriptFile != "" || !term.IsTerminal(int(os.Stdin.Fd()))
+	if wsScriptFile != "" && wsScriptFile != "-" {
+		realPath, err := validateFilePath(wsScriptFile)
+		if err != nil {
+			format.PrintError(fmt.Sprintf("Failed to read file: %v", err))
+			os.Exit(1)
+		}
+		f, err := os.Open(realPath)
+		if err != nil {
+			format.PrintError(fmt.Sprintf("Failed to read file: %v", err))
+			os.Exit(1)
+		}
+		defer f.Close()
+		input = f
+	}
+
+	var clientOpts []httpclient.Option
+	var jar *httpclient.SessionJar
+	if sessionName != "" {
+		jar, err = loadSessionJar(sessionName)
+		if err != nil {
+			format.PrintError(fmt.Sprintf("Failed to load session: %v", err))
+			os.Exit(1)
+		}
+		clientOpts = append(clientOpts, httpclient.WithCookieJar(jar))
+	}
+
+	client := httpclient.NewClient(clientOpts...)
+	start := time.Now()
+	ws, err := client.DialWebSocket(sendWSURL, sendHeaders, maxHistoryBodySize)
+	if err != nil {
+		format.PrintError(fmt.Sprintf("Connection failed: %v", err))
+		os.Exit(1)
+	}
+
+	format.PrintResponseHead(ws.Handshake, verbose)
+	if !scripted {
+		format.PrintStreamNotice("connected; type a message and press Enter to send, Ctrl-D to close")
+	}
+
+	// Print messages from the server as they arrive
+	received := make(chan error, 1)
+	go func() {
+		for {
+			data, binary, err := ws.Receive()
+			if err != nil {
+				received <- err
+				return
+			}
+			format.PrintWebSocketMessage(false, data, binary)
+		}
+	}()
+
+	// Read outgoing messages, one per line
+	lines := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(input)
+		scanner.Buffer(make([]byte, 64*1024), maxWebSocketMessage)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var serverErr error
+	serverClosed := false
+loop:
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				break loop
+			}
+			if scripted && strings.TrimSpace(line) == "" {
+				continue
+			}
+			if err := ws.SendText(line); err != nil {
+				format.PrintError(fmt.Sprintf("Failed to send message: %v", err))
+				break loop
+			}
+			// Typed messages are already on screen
+			if scripted {
+				format.PrintWebSocketMessage(true, line, false)
+			}
+		case serverErr = <-received:
+			serverClosed = true
+			break loop
+		case <-ctx.Done():
+			break loop
+		}
+	}
+
+	// Give the server a chance to answer the last scripted message
+	if scripted && !serverClosed && ctx.Err() == nil {
+		select {
+		case serverErr = <-received:
+			serverClosed = true
+		case <-time.After(wsWait):
+		case <-ctx.Done():
+		}
+	}
+
+	ws.Close()
+	switch {
+	case serverClosed && serverErr != io.EOF:
+		format.PrintError(fmt.Sprintf("Connection lost: %v", serverErr))
+	case serverClosed:
+		format.PrintStreamNotice("connection closed by server")
+	default:
+		format.PrintStreamNotice("connection closed")
+	}
+
+	if jar != nil {
+		redactSessionCookies(jar)
+		if err := saveSessionJar(sessionName, jar); err != nil {
+			format.PrintError(fmt.Sprintf("Failed to save session: %v", err))
+		}
+	}
+
+	if !noHistory {
+		resp := *ws.Handshake
+		resp.Body = ws.Transcript()
+		resp.DurationMs = time.Since(start).Milliseconds()
+		saveToHistory("WS", wsURL, headerMap, "", &resp)
+	}
+}
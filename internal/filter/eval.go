@@ -0,0 +1,581 @@
+package filter
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"unicode/utf8"
+)
+
+// node is a filter expression; eval returns its outputs for one input value
+type node interface {
+	eval(v interface{}) ([]interface{}, error)
+}
+
+// identityNode is "."
+type identityNode struct{}
+
+func (identityNode) eval(v interface{}) ([]interface{}, error) {
+	return []interface{}{v}, nil
+}
+
+// literalNode is a string, number, boolean or null constant
+type literalNode struct {
+	value interface{}
+}
+
+func (n literalNode) eval(interface{}) ([]interface{}, error) {
+	return []interface{}{n.value}, nil
+}
+
+// fieldNode is .name
+type fieldNode struct {
+	name string
+}
+
+func (n fieldNode) eval(v interface{}) ([]interface{}, error) {
+	switch obj := v.(type) {
+	case nil:
+		return []interface{}{nil}, nil
+	case map[string]interface{}:
+		return []interface{}{obj[n.name]}, nil
+	}
+	return nil, fmt.Errorf("cannot get field %q of %s", n.name, typeName(v))
+}
+
+// pipeNode feeds each output of left into right
+type pipeNode struct {
+	left, right node
+}
+
+func (n pipeNode) eval(v interface{}) ([]interface{}, error) {
+	inputs, err := n.left.eval(v)
+	if err != nil {
+		return nil, err
+	}
+	var results []interface{}
+	for _, in := range inputs {
+		out, err := n.right.eval(in)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, out...)
+	}
+	return results, nil
+}
+
+// commaNode is a, b: the outputs of each expression in turn
+type commaNode []node
+
+func (n commaNode) eval(v interface{}) ([]interface{}, error) {
+	var results []interface{}
+	for _, expr := range n {
+		out, err := expr.eval(v)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, out...)
+	}
+	return results, nil
+}
+
+// collectNode is [expr]: the outputs of expr gathered into an array
+type collectNode struct {
+	expr node
+}
+
+func (n collectNode) eval(v interface{}) ([]interface{}, error) {
+	items := []interface{}{}
+	if n.expr != nil {
+		out, err := n.expr.eval(v)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, out...)
+	}
+	return []interface{}{items}, nil
+}
+
+// iterateNode is .[]: each element of an array, or each value of an object
+type iterateNode struct{}
+
+func (iterateNode) eval(v interface{}) ([]interface{}, error) {
+	switch val := v.(type) {
+	case []interface{}:
+		return val, nil
+	case map[string]interface{}:
+		results := make([]interface{}, 0, len(val))
+		for _, k := range sortedKeys(val) {
+			results = append(results, val[k])
+		}
+		return results, nil
+	}
+	return nil, fmt.Errorf("cannot iterate over %s", typeName(v))
+}
+
+// indexNode is term[index], where index is a number or a field name
+type indexNode struct {
+	term, index node
+}
+
+func (n indexNode) eval(v interface{}) ([]interface{}, error) {
+	terms, err := n.term.eval(v)
+	if err != nil {
+		return nil, err
+	}
+	indexes, err := n.index.eval(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []interface{}
+	for _, t := range terms {
+		for _, idx := range indexes {
+			if name, ok := idx.(string); ok {
+				out, err := fieldNode{name}.eval(t)
+				if err != nil {
+					return nil, err
+				}
+				results = append(results, out...)
+				continue
+			}
+
+			i, ok := toNumber(idx)
+			if !ok {
+				return nil, fmt.Errorf("cannot index with %s", typeName(idx))
+			}
+			switch arr := t.(type) {
+			case nil:
+				results = append(results, nil)
+			case []interface{}:
+				pos := int(i)
+				if pos < 0 {
+					pos += len(arr)
+				}
+				if pos < 0 || pos >= len(arr) {
+					results = append(results, nil)
+				} else {
+					results = append(results, arr[pos])
+				}
+			default:
+				return nil, fmt.Errorf("cannot index %s with a number", typeName(t))
+			}
+		}
+	}
+	return results, nil
+}
+
+// sliceNode is term[from:to] on an array or string; either bound may be omitted
+type sliceNode struct {
+	term, from, to node
+}
+
+func (n sliceNode) eval(v interface{}) ([]interface{}, error) {
+	terms, err := n.term.eval(v)
+	if err != nil {
+		return nil, err
+	}
+	from, err := n.bound(n.from, v)
+	if err != nil {
+		return nil, err
+	}
+	to, err := n.bound(n.to, v)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []interface{}
+	for _, t := range terms {
+		switch val := t.(type) {
+		case nil:
+			results = append(results, nil)
+		case []interface{}:
+			start, end := sliceRange(from, to, len(val))
+			results = append(results, val[start:end])
+		case string:
+			runes := []rune(val)
+			start, end := sliceRange(from, to, len(runes))
+			results = append(results, string(runes[start:end]))
+		default:
+			return nil, fmt.Errorf("cannot slice %s", typeName(t))
+		}
+	}
+	return results, nil
+}
+
+// bound evaluates an optional slice bound
+func (n sliceNode) bound(expr node, v interface{}) (*int, error) {
+	if expr == nil {
+		return nil, nil
+	}
+	out, err := expr.eval(v)
+	if err != nil {
+		return nil, err
+	}
+	if len(out) != 1 {
+		return nil, fmt.Errorf("slice bound must be a single number")
+	}
+	f, ok := toNumber(out[0])
+	if !ok {
+		return nil, fmt.Errorf("slice bound must be a number, not %s", typeName(out[0]))
+	}
+	i := int(f)
+	return &i, nil
+}
+
+// sliceRange resolves optional, possibly negative, bounds against a length
+func sliceRange(from, to *int, length int) (int, int) {
+	clamp := func(i *int, def int) int {
+		if i == nil {
+			return def
+		}
+		pos := *i
+		if pos < 0 {
+			pos += length
+		}
+		if pos < 0 {
+			return 0
+		}
+		if pos > length {
+			return length
+		}
+		return pos
+	}
+	start, end := clamp(from, 0), clamp(to, length)
+	if end < start {
+		end = start
+	}
+	return start, end
+}
+
+// recurseNode is "..": the input and every value nested within it. JSONPath's
+// .. only visits arrays and objects.
+type recurseNode struct {
+	containers bool
+}
+
+func (n recurseNode) eval(v interface{}) ([]interface{}, error) {
+	var results []interface{}
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		switch v.(type) {
+		case []interface{}, map[string]interface{}:
+			results = append(results, v)
+		default:
+			if !n.containers {
+				results = append(results, v)
+			}
+		}
+		switch val := v.(type) {
+		case []interface{}:
+			for _, item := range val {
+				walk(item)
+			}
+		case map[string]interface{}:
+			for _, k := range sortedKeys(val) {
+				walk(val[k])
+			}
+		}
+	}
+	walk(v)
+	return results, nil
+}
+
+// recurseFieldNode is JSONPath's $..name: the field wherever it appears
+type recurseFieldNode struct {
+	name string
+}
+
+func (n recurseFieldNode) eval(v interface{}) ([]interface{}, error) {
+	all, _ := recurseNode{containers: true}.eval(v)
+	var results []interface{}
+	for _, item := range all {
+		if obj, ok := item.(map[string]interface{}); ok {
+			if value, ok := obj[n.name]; ok {
+				results = append(results, value)
+			}
+		}
+	}
+	return results, nil
+}
+
+// tryNode is expr?: errors produce no output instead of failing the filter
+type tryNode struct {
+	expr node
+}
+
+func (n tryNode) eval(v interface{}) ([]interface{}, error) {
+	out, err := n.expr.eval(v)
+	if err != nil {
+		return nil, nil
+	}
+	return out, nil
+}
+
+// selectNode is select(cond): the input, if cond is true for it
+type selectNode struct {
+	cond node
+}
+
+func (n selectNode) eval(v interface{}) ([]interface{}, error) {
+	out, err := n.cond.eval(v)
+	if err != nil {
+		return nil, err
+	}
+	var results []interface{}
+	for _, c := range out {
+		if truthy(c) {
+			results = append(results, v)
+		}
+	}
+	return results, nil
+}
+
+// lengthNode is length
+type lengthNode struct{}
+
+func (lengthNode) eval(v interface{}) ([]interface{}, error) {
+	switch val := v.(type) {
+	case nil:
+		return []interface{}{0}, nil
+	case string:
+		return []interface{}{utf8.RuneCountInString(val)}, nil
+	case []interface{}:
+		return []interface{}{len(val)}, nil
+	case map[string]interface{}:
+		return []interface{}{len(val)}, nil
+	}
+	if f, ok := toNumber(v); ok {
+		if f < 0 {
+			f = -f
+		}
+		return []interface{}{f}, nil
+	}
+	return nil, fmt.Errorf("%s has no length", typeName(v))
+}
+
+// keysNode is keys: an object's sorted keys, or an array's indexes
+type keysNode struct{}
+
+func (keysNode) eval(v interface{}) ([]interface{}, error) {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		keys := []interface{}{}
+		for _, k := range sortedKeys(val) {
+			keys = append(keys, k)
+		}
+		return []interface{}{keys}, nil
+	case []interface{}:
+		keys := make([]interface{}, len(val))
+		for i := range val {
+			keys[i] = i
+		}
+		return []interface{}{keys}, nil
+	}
+	return nil, fmt.Errorf("%s has no keys", typeName(v))
+}
+
+// notNode is not
+type notNode struct{}
+
+func (notNode) eval(v interface{}) ([]interface{}, error) {
+	return []interface{}{!truthy(v)}, nil
+}
+
+// logicNode is a and b, or a or b
+type logicNode struct {
+	and         bool
+	left, right node
+}
+
+func (n logicNode) eval(v interface{}) ([]interface{}, error) {
+	lefts, err := n.left.eval(v)
+	if err != nil {
+		return nil, err
+	}
+	var results []interface{}
+	for _, l := range lefts {
+		// Short-circuit as jq does
+		if truthy(l) != n.and {
+			results = append(results, !n.and)
+			continue
+		}
+		rights, err := n.right.eval(v)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range rights {
+			results = append(results, truthy(r))
+		}
+	}
+	return results, nil
+}
+
+// compareNode is a comparison between every pair of outputs of left and right
+type compareNode struct {
+	op          string
+	left, right node
+}
+
+func (n compareNode) eval(v interface{}) ([]interface{}, error) {
+	lefts, err := n.left.eval(v)
+	if err != nil {
+		return nil, err
+	}
+	rights, err := n.right.eval(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []interface{}
+	for _, r := range rights {
+		for _, l := range lefts {
+			c := compareValues(l, r)
+			var result bool
+			switch n.op {
+			case "==":
+				result = c == 0
+			case "!=":
+				result = c != 0
+			case "<":
+				result = c < 0
+			case "<=":
+				result = c <= 0
+			case ">":
+				result = c > 0
+			case ">=":
+				result = c >= 0
+			}
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
+
+// compareValues orders any two JSON values the way jq does: null, false,
+// true, numbers, strings, arrays, then objects
+func compareValues(a, b interface{}) int {
+	ra, rb := typeRank(a), typeRank(b)
+	if ra != rb {
+		return ra - rb
+	}
+
+	switch av := a.(type) {
+	case string:
+		bv := b.(string)
+		switch {
+		case av < bv:
+			return -1
+		case av > bv:
+			return 1
+		}
+		return 0
+	case []interface{}:
+		bv := b.([]interface{})
+		for i := 0; i < len(av) && i < len(bv); i++ {
+			if c := compareValues(av[i], bv[i]); c != 0 {
+				return c
+			}
+		}
+		return len(av) - len(bv)
+	case map[string]interface{}:
+		bv := b.(map[string]interface{})
+		ka, kb := sortedKeys(av), sortedKeys(bv)
+		keysA, keysB := make([]interface{}, len(ka)), make([]interface{}, len(kb))
+		for i, k := range ka {
+			keysA[i] = k
+		}
+		for i, k := range kb {
+			keysB[i] = k
+		}
+		if c := compareValues(keysA, keysB); c != 0 {
+			return c
+		}
+		for _, k := range ka {
+			if c := compareValues(av[k], bv[k]); c != 0 {
+				return c
+			}
+		}
+		return 0
+	}
+
+	if fa, ok := toNumber(a); ok {
+		fb, _ := toNumber(b)
+		switch {
+		case fa < fb:
+			return -1
+		case fa > fb:
+			return 1
+		}
+	}
+	return 0
+}
+
+func typeRank(v interface{}) int {
+	switch val := v.(type) {
+	case nil:
+		return 0
+	case bool:
+		if val {
+			return 2
+		}
+		return 1
+	case string:
+		return 4
+	case []interface{}:
+		return 5
+	case map[string]interface{}:
+		return 6
+	}
+	return 3
+}
+
+// truthy reports whether v counts as true: anything but false and null
+func truthy(v interface{}) bool {
+	if v == nil {
+		return false
+	}
+	if b, ok := v.(bool); ok {
+		return b
+	}
+	return true
+}
+
+// toNumber converts a decoded JSON number, or a number computed by the filter, to float64
+func toNumber(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case int:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+func typeName(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "a boolean"
+	case string:
+		return "a string"
+	case []interface{}:
+		return "an array"
+	case map[string]interface{}:
+		return "an object"
+	}
+	return "a number"
+}
+
+func sortedKeys(obj map[string]interface{}) []string {
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,460 @@
+// Package filter selects parts of JSON documents using a subset of jq or
+// JSONPath.
+//
+// jq expressions support field access (.a.b, .["a"]), indexing and slicing
+// (.[0], .[-1], .[1:3]), iteration (.[]), recursion (..), pipes, commas,
+// array construction, literals, comparisons, and/or, and the functions
+// length, keys, map, select and not. Expressions starting with $ are
+// JSONPath: $.a.b, $['a'], $[0], $[*], $..a, $[0:2] and $[?(@.a > 1)].
+package filter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Filter is a parsed filter expression
+type Filter struct {
+	root node
+}
+
+// Parse parses a jq expression, or a JSONPath expression if it starts with $
+func Parse(expr string) (*Filter, error) {
+	tokens, err := lex(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter: %v", err)
+	}
+
+	p := &parser{tokens: tokens}
+	var root node
+	if strings.HasPrefix(strings.TrimSpace(expr), "$") {
+		root, err = p.parseJSONPath()
+	} else {
+		root, err = p.parsePipe()
+	}
+	if err == nil && p.peek().kind != tokEOF {
+		err = p.errorf("unexpected %q", p.peek().text)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter: %v", err)
+	}
+	return &Filter{root: root}, nil
+}
+
+// Apply runs the filter on a JSON document, returning each result as compact JSON
+func (f *Filter) Apply(body string) ([]string, error) {
+	decoder := json.NewDecoder(strings.NewReader(body))
+	decoder.UseNumber()
+	var input interface{}
+	if err := decoder.Decode(&input); err != nil {
+		return nil, fmt.Errorf("body is not JSON: %v", err)
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, fmt.Errorf("body is not a single JSON document")
+	}
+
+	values, err := f.root.eval(input)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]string, 0, len(values))
+	for _, v := range values {
+		var buf bytes.Buffer
+		encoder := json.NewEncoder(&buf)
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(v); err != nil {
+			return nil, err
+		}
+		results = append(results, strings.TrimSuffix(buf.String(), "\n"))
+	}
+	return results, nil
+}
+
+// parser builds a node tree from tokens
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+// accept consumes the next token if it is the punctuation or keyword s
+func (p *parser) accept(s string) bool {
+	t := p.peek()
+	if (t.kind == tokPunct || t.kind == tokIdent) && t.text == s {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(s string) error {
+	if !p.accept(s) {
+		return p.errorf("expected %q", s)
+	}
+	return nil
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	t := p.peek()
+	if t.kind == tokEOF {
+		return fmt.Errorf(format+" at end of expression", args...)
+	}
+	return fmt.Errorf(format+" at position %d", append(args, t.pos+1)...)
+}
+
+// parsePipe parses a | b | c
+func (p *parser) parsePipe() (node, error) {
+	left, err := p.parseComma()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("|") {
+		right, err := p.parseComma()
+		if err != nil {
+			return nil, err
+		}
+		left = pipeNode{left, right}
+	}
+	return left, nil
+}
+
+// parseComma parses a, b, c
+func (p *parser) parseComma() (node, error) {
+	first, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	nodes := commaNode{first}
+	for p.accept(",") {
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, n)
+	}
+	if len(nodes) == 1 {
+		return first, nil
+	}
+	return nodes, nil
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = logicNode{and: false, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseCompare()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("and") {
+		right, err := p.parseCompare()
+		if err != nil {
+			return nil, err
+		}
+		left = logicNode{and: true, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseCompare() (node, error) {
+	left, err := p.parsePostfix()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind == tokPunct {
+		switch t.text {
+		case "==", "!=", "<", "<=", ">", ">=":
+			p.next()
+			right, err := p.parsePostfix()
+			if err != nil {
+				return nil, err
+			}
+			return compareNode{op: t.text, left: left, right: right}, nil
+		}
+	}
+	return left, nil
+}
+
+// parsePostfix parses a term followed by any number of .field and [...] suffixes
+func (p *parser) parsePostfix() (node, error) {
+	term, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch {
+		case p.peek().text == "." && p.peek().kind == tokPunct:
+			p.next()
+			field, err := p.parseFieldName()
+			if err != nil {
+				return nil, err
+			}
+			term = pipeNode{term, field}
+		case p.peek().text == "[" && p.peek().kind == tokPunct:
+			p.next()
+			suffix, err := p.parseBracket(term)
+			if err != nil {
+				return nil, err
+			}
+			term = suffix
+		case p.peek().text == "?" && p.peek().kind == tokPunct:
+			p.next()
+			term = tryNode{term}
+		default:
+			return term, nil
+		}
+	}
+}
+
+// parseFieldName parses the name after a "." (an identifier or quoted string)
+func (p *parser) parseFieldName() (node, error) {
+	t := p.peek()
+	if t.kind == tokIdent || t.kind == tokString {
+		p.next()
+		return fieldNode{t.text}, nil
+	}
+	return nil, p.errorf("expected a field name")
+}
+
+// parseBracket parses what follows "[" after term: [], [index], or [from:to]
+func (p *parser) parseBracket(term node) (node, error) {
+	if p.accept("]") {
+		return pipeNode{term, iterateNode{}}, nil
+	}
+
+	var from, to node
+	var err error
+	if p.peek().text != ":" {
+		if from, err = p.parsePipe(); err != nil {
+			return nil, err
+		}
+	}
+	if p.accept(":") {
+		if p.peek().text != "]" {
+			if to, err = p.parsePipe(); err != nil {
+				return nil, err
+			}
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+		return sliceNode{term: term, from: from, to: to}, nil
+	}
+	if err := p.expect("]"); err != nil {
+		return nil, err
+	}
+	return indexNode{term: term, index: from}, nil
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokNumber:
+		return literalNode{json.Number(t.text)}, nil
+	case tokString:
+		return literalNode{t.text}, nil
+	case tokIdent:
+		return p.parseFunction(t)
+	case tokEOF:
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+
+	switch t.text {
+	case ".":
+		// .field, .[...] or . on its own
+		if n := p.peek(); n.kind == tokIdent || n.kind == tokString {
+			return p.parseFieldName()
+		}
+		return identityNode{}, nil
+	case "@":
+		// JSONPath's current item in a filter
+		return identityNode{}, nil
+	case "..":
+		return recurseNode{}, nil
+	case "(":
+		n, err := p.parsePipe()
+		if err != nil {
+			return nil, err
+		}
+		return n, p.expect(")")
+	case "[":
+		if p.accept("]") {
+			return collectNode{nil}, nil
+		}
+		n, err := p.parsePipe()
+		if err != nil {
+			return nil, err
+		}
+		return collectNode{n}, p.expect("]")
+	}
+	p.pos--
+	return nil, p.errorf("unexpected %q", t.text)
+}
+
+// parseFunction parses a keyword, literal or function call starting with t
+func (p *parser) parseFunction(t token) (node, error) {
+	switch t.text {
+	case "true":
+		return literalNode{true}, nil
+	case "false":
+		return literalNode{false}, nil
+	case "null":
+		return literalNode{nil}, nil
+	case "length":
+		return lengthNode{}, nil
+	case "keys":
+		return keysNode{}, nil
+	case "not":
+		return notNode{}, nil
+	case "map", "select":
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		arg, err := p.parsePipe()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		if t.text == "map" {
+			return collectNode{pipeNode{iterateNode{}, arg}}, nil
+		}
+		return selectNode{arg}, nil
+	}
+	p.pos--
+	return nil, p.errorf("unknown function %q", t.text)
+}
+
+// parseJSONPath parses $ followed by JSONPath segments
+func (p *parser) parseJSONPath() (node, error) {
+	if err := p.expect("$"); err != nil {
+		return nil, err
+	}
+
+	var path node = identityNode{}
+	for {
+		switch {
+		case p.accept(".."):
+			// $..name, $..[...] and $..* search every level
+			if p.accept("*") {
+				path = pipeNode{path, pipeNode{recurseNode{containers: true}, iterateNode{}}}
+			} else if t := p.peek(); t.kind == tokIdent || t.kind == tokString {
+				p.next()
+				path = pipeNode{path, recurseFieldNode{t.text}}
+			} else if p.accept("[") {
+				seg, err := p.parsePathBracket()
+				if err != nil {
+					return nil, err
+				}
+				path = pipeNode{path, pipeNode{recurseNode{containers: true}, tryNode{seg}}}
+			} else {
+				return nil, p.errorf("expected a field name after ..")
+			}
+		case p.accept("."):
+			if p.accept("*") {
+				path = pipeNode{path, iterateNode{}}
+				continue
+			}
+			field, err := p.parseFieldName()
+			if err != nil {
+				return nil, err
+			}
+			path = pipeNode{path, field}
+		case p.accept("["):
+			seg, err := p.parsePathBracket()
+			if err != nil {
+				return nil, err
+			}
+			path = pipeNode{path, seg}
+		default:
+			return path, nil
+		}
+	}
+}
+
+// parsePathBracket parses a JSONPath [...] segment after its "["
+func (p *parser) parsePathBracket() (node, error) {
+	// [*]
+	if p.accept("*") {
+		return iterateNode{}, p.expect("]")
+	}
+
+	// [?(@.a > 1)]
+	if p.accept("?") {
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		cond, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return pipeNode{iterateNode{}, selectNode{cond}}, p.expect("]")
+	}
+
+	// [start:end]
+	if p.peek().text == ":" || p.peek().kind == tokNumber && p.tokens[p.pos+1].text == ":" {
+		var from, to node
+		if p.peek().kind == tokNumber {
+			from = literalNode{json.Number(p.next().text)}
+		}
+		p.next()
+		if p.peek().kind == tokNumber {
+			to = literalNode{json.Number(p.next().text)}
+		}
+		// JSONPath selects the elements of the slice, not the slice itself
+		return pipeNode{sliceNode{term: identityNode{}, from: from, to: to}, iterateNode{}}, p.expect("]")
+	}
+
+	// [0], ['name'] or a union like [0,1] / ['a','b']
+	var keys commaNode
+	for {
+		t := p.next()
+		switch t.kind {
+		case tokNumber:
+			keys = append(keys, indexNode{term: identityNode{}, index: literalNode{json.Number(t.text)}})
+		case tokString, tokIdent:
+			keys = append(keys, fieldNode{t.text})
+		default:
+			p.pos--
+			return nil, p.errorf("expected an index or quoted name")
+		}
+		if !p.accept(",") {
+			break
+		}
+	}
+	if len(keys) == 1 {
+		return keys[0], p.expect("]")
+	}
+	return keys, p.expect("]")
+}
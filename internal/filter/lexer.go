@@ -0,0 +1,150 @@
+package filter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// tokenKind classifies a token of a filter expression
+type tokenKind int
+
+const (
+	tokEOF    tokenKind = iota
+	tokPunct            // . .. [ ] ( ) | , : * @ $ and comparison operators
+	tokIdent            // field or function name
+	tokString           // quoted string, already unescaped
+	tokNumber
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// lex splits an expression into tokens. JSONPath's && and || are read as
+// jq's "and" and "or".
+func lex(expr string) ([]token, error) {
+	var tokens []token
+	i := 0
+	for i < len(expr) {
+		c := expr[i]
+		start := i
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+			continue
+
+		case c == '"' || c == '\'':
+			s, n, err := lexString(expr[i:])
+			if err != nil {
+				return nil, fmt.Errorf("at position %d: %v", start+1, err)
+			}
+			tokens = append(tokens, token{tokString, s, start})
+			i += n
+			continue
+
+		case c >= '0' && c <= '9' || c == '-' && i+1 < len(expr) && isDigit(expr[i+1]) && !followsValue(tokens):
+			i++
+			for i < len(expr) && (isDigit(expr[i]) || strings.IndexByte(".eE+-", expr[i]) >= 0) {
+				// A sign is only part of the number right after an exponent
+				if (expr[i] == '+' || expr[i] == '-') && expr[i-1] != 'e' && expr[i-1] != 'E' {
+					break
+				}
+				i++
+			}
+			if _, err := strconv.ParseFloat(expr[start:i], 64); err != nil {
+				return nil, fmt.Errorf("at position %d: invalid number %q", start+1, expr[start:i])
+			}
+			tokens = append(tokens, token{tokNumber, expr[start:i], start})
+			continue
+
+		case c == '_' || unicode.IsLetter(rune(c)):
+			for i < len(expr) && (expr[i] == '_' || isDigit(expr[i]) || unicode.IsLetter(rune(expr[i]))) {
+				i++
+			}
+			tokens = append(tokens, token{tokIdent, expr[start:i], start})
+			continue
+		}
+
+		// Punctuation, longest match first
+		p := punctAt(expr[i:])
+		if p == "" {
+			return nil, fmt.Errorf("at position %d: unexpected %q", start+1, string(c))
+		}
+		switch p {
+		case "&&":
+			tokens = append(tokens, token{tokIdent, "and", start})
+		case "||":
+			tokens = append(tokens, token{tokIdent, "or", start})
+		default:
+			tokens = append(tokens, token{tokPunct, p, start})
+		}
+		i += len(p)
+	}
+	return append(tokens, token{tokEOF, "", len(expr)}), nil
+}
+
+// punctuation lists the punctuation tokens, longer ones before their prefixes
+var punctuation = []string{"..", "==", "!=", "<=", ">=", "&&", "||", ".", "[", "]", "(", ")", "|", ",", ":", "*", "@", "$", "<", ">", "?"}
+
+// punctAt returns the punctuation token at the start of s, or ""
+func punctAt(s string) string {
+	for _, p := range punctuation {
+		if strings.HasPrefix(s, p) {
+			return p
+		}
+	}
+	return ""
+}
+
+// lexString reads a quoted string at the start of s, returning its value and
+// the number of bytes consumed
+func lexString(s string) (string, int, error) {
+	quote := s[0]
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == quote:
+			if quote == '"' {
+				// Double-quoted strings use JSON escapes
+				value, err := strconv.Unquote(s[:i+1])
+				if err != nil {
+					return "", 0, fmt.Errorf("invalid string %s", s[:i+1])
+				}
+				return value, i + 1, nil
+			}
+			return b.String(), i + 1, nil
+		case c == '\\' && i+1 < len(s):
+			i++
+			b.WriteByte(s[i])
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated string")
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// followsValue reports whether the last token ends a value, in which case a
+// following "-" can't start a negative number
+func followsValue(tokens []token) bool {
+	if len(tokens) == 0 {
+		return false
+	}
+	last := tokens[len(tokens)-1]
+	switch last.kind {
+	case tokNumber, tokString:
+		return true
+	case tokIdent:
+		return last.text != "and" && last.text != "or"
+	}
+	return last.text == "]" || last.text == ")"
+}
@@ -0,0 +1,126 @@
+package format
+
+import (
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"api/internal/model"
+	"golang.org/x/term"
+)
+
+// Hexdump makes binary bodies print as a hex dump instead of a summary
+var Hexdump bool
+
+// binarySniffLen is how much of a body is inspected when the content type is unknown
+const binarySniffLen = 8 * 1024
+
+// binaryMediaTypes are non-text types that don't share a common prefix
+var binaryMediaTypes = map[string]bool{
+	"application/octet-stream":        true,
+	"application/pdf":                 true,
+	"application/zip":                 true,
+	"application/gzip":                true,
+	"application/x-gzip":              true,
+	"application/x-tar":               true,
+	"application/x-7z-compressed":     true,
+	"application/protobuf":            true,
+	"application/x-protobuf":          true,
+	"application/vnd.google.protobuf": true,
+	"application/grpc":                true,
+	"application/msgpack":             true,
+	"application/x-msgpack":           true,
+	"application/cbor":                true,
+	"application/wasm":                true,
+}
+
+// textMediaTypes are application/* types that carry text
+var textMediaTypes = map[string]bool{
+	"application/json":                  true,
+	"application/xml":                   true,
+	"application/javascript":            true,
+	"application/x-javascript":          true,
+	"application/ecmascript":            true,
+	"application/x-www-form-urlencoded": true,
+	"application/graphql":               true,
+	"application/x-ndjson":              true,
+	"application/yaml":                  true,
+	"application/x-yaml":                true,
+	"application/toml":                  true,
+	"application/sql":                   true,
+}
+
+// IsBinary reports whether a body should be treated as binary data. The
+// content type decides when it is known; otherwise the body is sniffed.
+func IsBinary(contentType, body string) bool {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		switch {
+		case strings.HasPrefix(mediaType, "text/"),
+			strings.HasSuffix(mediaType, "+json"),
+			strings.HasSuffix(mediaType, "+xml"),
+			textMediaTypes[mediaType]:
+			return false
+		case strings.HasPrefix(mediaType, "image/"),
+			strings.HasPrefix(mediaType, "audio/"),
+			strings.HasPrefix(mediaType, "video/"),
+			strings.HasPrefix(mediaType, "font/"),
+			strings.HasSuffix(mediaType, "+proto"),
+			binaryMediaTypes[mediaType]:
+			return true
+		}
+	}
+
+	return looksBinary(body)
+}
+
+// looksBinary sniffs the start of a body for NUL bytes or invalid UTF-8
+func looksBinary(body string) bool {
+	sample := body
+	if len(sample) > binarySniffLen {
+		sample = sample[:binarySniffLen]
+	}
+
+	for i, r := range sample {
+		if r == 0 {
+			return true
+		}
+		// A rune cut off at the end of the sample is not evidence of binary data
+		if r == utf8.RuneError && len(sample)-i > utf8.UTFMax {
+			return true
+		}
+	}
+	return false
+}
+
+// printBinaryBody prints a summary of a binary body, or a hex dump if enabled
+func printBinaryBody(body, contentType string) {
+	if Hexdump {
+		fmt.Print(hex.Dump([]byte(body)))
+		return
+	}
+
+	if contentType == "" {
+		contentType = "unknown type"
+	}
+	dimColor.Printf("(binary body: %s, %s)\n", FormatBytes(int64(len(body))), sanitizeOutput(contentType))
+	dimColor.Println("Use --hexdump to view it or --raw to write it to a pipe or file")
+}
+
+// PrintRaw writes a response body exactly as received, for piping to other
+// programs. Binary data is never written to a terminal, and text written to a
+// terminal is still sanitized.
+func PrintRaw(resp *model.Response) error {
+	if !term.IsTerminal(int(os.Stdout.Fd())) {
+		_, err := os.Stdout.WriteString(resp.Body)
+		return err
+	}
+
+	if IsBinary(resp.ContentType, resp.Body) {
+		return fmt.Errorf("refusing to write binary data to a terminal (redirect the output or use --hexdump)")
+	}
+	_, err := os.Stdout.WriteString(sanitizeOutput(resp.Body))
+	return err
+}
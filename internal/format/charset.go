@@ -0,0 +1,59 @@
+package format
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html/charset"
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+// utf8BOM is dropped from the start of decoded text
+const utf8BOM = "\uFEFF"
+
+// charsetSniffLen is how much of a body is searched for a BOM or <meta> charset
+const charsetSniffLen = 1024
+
+// metaCharsetPattern finds an HTML <meta> tag declaring a charset
+var metaCharsetPattern = regexp.MustCompile(`(?i)<meta[^>]+charset`)
+
+// xmlEncodingPattern finds the encoding in an XML declaration
+var xmlEncodingPattern = regexp.MustCompile(`^<\?xml[^>]*\sencoding\s*=\s*["']([A-Za-z0-9._:-]+)["']`)
+
+// DecodeText converts a text body to UTF-8 for display, using the charset in
+// its Content-Type, a byte order mark, an HTML <meta> charset or an XML
+// declaration. Bodies without a declared charset are returned unchanged, as
+// are bodies that fail to decode.
+func DecodeText(contentType, body string) string {
+	sample := body
+	if len(sample) > charsetSniffLen {
+		sample = sample[:charsetSniffLen]
+	}
+
+	// A <meta> charset is reported as uncertain, like the windows-1252 guess
+	// made when nothing is declared; only a guess is ignored
+	enc, name, certain := charset.DetermineEncoding([]byte(sample), contentType)
+	if !certain && (name != "windows-1252" || metaCharsetPattern.MatchString(sample)) {
+		certain = true
+	}
+	if !certain {
+		if m := xmlEncodingPattern.FindStringSubmatch(sample); m != nil {
+			if e, err := htmlindex.Get(m[1]); err == nil {
+				enc, certain = e, true
+				name, _ = htmlindex.Name(e)
+			}
+		}
+	}
+	if !certain {
+		return body
+	}
+	if name == "utf-8" {
+		return strings.TrimPrefix(body, utf8BOM)
+	}
+
+	decoded, err := enc.NewDecoder().String(body)
+	if err != nil {
+		return body
+	}
+	return strings.TrimPrefix(decoded, utf8BOM)
+}
@@ -0,0 +1,176 @@
+package format
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"api/internal/model"
+)
+
+// graphQLKindOrder lists type kinds in the order PrintGraphQLSchema groups them
+var graphQLKindOrder = []string{"OBJECT", "INTERFACE", "UNION", "INPUT_OBJECT", "ENUM", "SCALAR"}
+
+// PrintGraphQLResponse prints a GraphQL response: any errors, highlighted,
+// followed by the pretty-printed data
+func PrintGraphQLResponse(resp *model.Response, result *model.GraphQLResult, showHeaders bool) {
+	printResponseHead(resp, showHeaders)
+
+	if len(result.Errors) > 0 {
+		clientErrColor.Printf("Errors (%d):\n", len(result.Errors))
+		for _, e := range result.Errors {
+			clientErrColor.Printf("  ✗ %s", sanitizeOutput(e.Message))
+			if len(e.Locations) > 0 {
+				locs := make([]string, len(e.Locations))
+				for i, l := range e.Locations {
+					locs[i] = fmt.Sprintf("%d:%d", l.Line, l.Column)
+				}
+				dimColor.Printf("  (at %s)", strings.Join(locs, ", "))
+			}
+			fmt.Println()
+			if len(e.Path) > 0 {
+				dimColor.Printf("    path: %s\n", sanitizeOutput(graphQLPath(e.Path)))
+			}
+		}
+		fmt.Println()
+	}
+
+	if result.Data == nil {
+		dimColor.Println("(no data)")
+		return
+	}
+	fmt.Println(highlightJSON(string(result.Data)))
+}
+
+// graphQLPath formats an error path like user.friends[0].name
+func graphQLPath(path []interface{}) string {
+	var b strings.Builder
+	for _, segment := range path {
+		switch s := segment.(type) {
+		case float64:
+			fmt.Fprintf(&b, "[%d]", int(s))
+		default:
+			if b.Len() > 0 {
+				b.WriteString(".")
+			}
+			fmt.Fprint(&b, s)
+		}
+	}
+	return b.String()
+}
+
+// PrintGraphQLSchema lists the types of an introspected schema, grouped by kind
+func PrintGraphQLSchema(schema *model.GraphQLSchema) {
+	fmt.Print("Schema")
+	roots := []struct{ label, name string }{
+		{"query", schema.QueryType},
+		{"mutation", schema.MutationType},
+		{"subscription", schema.SubscriptionType},
+	}
+	for _, r := range roots {
+		if r.name != "" {
+			dimColor.Printf("  %s: ", r.label)
+			headerKeyColor.Print(sanitizeOutput(r.name))
+		}
+	}
+	fmt.Println()
+
+	for _, kind := range graphQLKindOrder {
+		var types []model.GraphQLType
+		for _, t := range schema.Types {
+			if t.Kind == kind {
+				types = append(types, t)
+			}
+		}
+		if len(types) == 0 {
+			continue
+		}
+		sort.Slice(types, func(i, j int) bool { return types[i].Name < types[j].Name })
+
+		fmt.Printf("\n%s:\n", strings.ToLower(strings.ReplaceAll(kind, "_", " ")))
+		for _, t := range types {
+			headerKeyColor.Printf("  %s", sanitizeOutput(t.Name))
+			switch {
+			case len(t.Fields) > 0:
+				dimColor.Printf("  (%d fields)", len(t.Fields))
+			case len(t.EnumValues) > 0:
+				dimColor.Printf("  (%d values)", len(t.EnumValues))
+			case len(t.PossibleTypes) > 0:
+				dimColor.Printf("  = %s", sanitizeOutput(strings.Join(t.PossibleTypes, " | ")))
+			}
+			fmt.Println()
+		}
+	}
+}
+
+// PrintGraphQLType prints a type's fields with their arguments, or its values
+func PrintGraphQLType(t *model.GraphQLType) {
+	methodColor.Printf("%s ", strings.ToLower(strings.ReplaceAll(t.Kind, "_", " ")))
+	headerKeyColor.Println(sanitizeOutput(t.Name))
+	if t.Description != "" {
+		dimColor.Printf("  %s\n", sanitizeOutput(t.Description))
+	}
+	fmt.Println()
+
+	for _, f := range t.Fields {
+		fmt.Printf("  %s", sanitizeOutput(f.Name))
+		if len(f.Args) > 0 {
+			args := make([]string, len(f.Args))
+			for i, a := range f.Args {
+				args[i] = a.Name + ": " + a.Type
+				if a.DefaultValue != "" {
+					args[i] += " = " + a.DefaultValue
+				}
+			}
+			dimColor.Printf("(%s)", sanitizeOutput(strings.Join(args, ", ")))
+		}
+		fmt.Print(": ")
+		urlColor.Print(sanitizeOutput(f.Type))
+		if f.DefaultValue != "" {
+			dimColor.Printf(" = %s", sanitizeOutput(f.DefaultValue))
+		}
+		if f.Deprecated {
+			redirectColor.Print("  (deprecated)")
+		}
+		fmt.Println()
+		if f.Description != "" {
+			dimColor.Printf("      %s\n", sanitizeOutput(f.Description))
+		}
+	}
+	for _, v := range t.EnumValues {
+		fmt.Printf("  %s\n", sanitizeOutput(v))
+	}
+	if len(t.PossibleTypes) > 0 {
+		dimColor.Print("  possible types: ")
+		fmt.Println(sanitizeOutput(strings.Join(t.PossibleTypes, ", ")))
+	}
+}
+
+// printSavedGraphQLRequest prints a GraphQL request saved in a collection with
+// its operation name and variables
+func printSavedGraphQLRequest(req model.SavedRequest) {
+	methodColor.Print("GRAPHQL ")
+	urlColor.Println(sanitizeOutput(req.URL))
+	PrintQueryParams(req.Query, "      ")
+
+	var doc model.GraphQLRequest
+	if err := json.Unmarshal([]byte(req.Body), &doc); err != nil {
+		return
+	}
+	if doc.OperationName != "" {
+		dimColor.Print("      operation: ")
+		fmt.Println(sanitizeOutput(doc.OperationName))
+	}
+	if query := strings.Join(strings.Fields(doc.Query), " "); query != "" {
+		if len(query) > 60 {
+			query = query[:57] + "..."
+		}
+		dimColor.Print("      query: ")
+		fmt.Println(sanitizeOutput(query))
+	}
+	if len(doc.Variables) > 0 {
+		dimColor.Print("      variables: ")
+		fmt.Println(sanitizeOutput(string(doc.Variables)))
+	}
+}
@@ -0,0 +1,50 @@
+package format
+
+import (
+	"fmt"
+
+	"api/internal/model"
+)
+
+// PrintGRPCMessage prints a gRPC response message as it arrives
+func PrintGRPCMessage(msg string) {
+	fmt.Println(highlightJSON(msg))
+}
+
+// PrintGRPCStatus prints the status a gRPC call ended with and, if requested,
+// its response metadata and trailers
+func PrintGRPCStatus(resp *model.Response, showHeaders bool) {
+	fmt.Println()
+	printResponseHead(resp, showHeaders)
+}
+
+// PrintGRPCServices lists the services a gRPC server offers
+func PrintGRPCServices(services []string) {
+	if len(services) == 0 {
+		dimColor.Println("No services found")
+		return
+	}
+
+	fmt.Println("Services:")
+	for _, name := range services {
+		headerKeyColor.Printf("  %s\n", sanitizeOutput(name))
+	}
+}
+
+// PrintGRPCService lists a gRPC service's methods with their message types
+func PrintGRPCService(svc *model.GRPCService) {
+	headerKeyColor.Println(sanitizeOutput(svc.Name))
+	for _, m := range svc.Methods {
+		methodColor.Printf("  %s", sanitizeOutput(m.Name))
+		fmt.Printf("(%s) returns (%s)\n",
+			sanitizeOutput(streamPrefix(m.ClientStreaming)+m.InputType),
+			sanitizeOutput(streamPrefix(m.ServerStreaming)+m.OutputType))
+	}
+}
+
+func streamPrefix(streaming bool) string {
+	if streaming {
+		return "stream "
+	}
+	return ""
+}
@@ -0,0 +1,470 @@
+package format
+
+import (
+	"encoding/json"
+	"io"
+	"mime"
+	"regexp"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+var (
+	keyColor     = color.New(color.FgCyan)
+	stringColor  = color.New(color.FgGreen)
+	numberColor  = color.New(color.FgYellow)
+	literalColor = color.New(color.FgMagenta)
+	tagColor     = color.New(color.FgBlue)
+	attrColor    = color.New(color.FgCyan)
+	commentColor = color.New(color.Faint)
+)
+
+// Syntaxes a body can be highlighted as
+const (
+	syntaxNone = iota
+	syntaxJSON
+	syntaxXML
+	syntaxHTML
+	syntaxYAML
+)
+
+// detectSyntax picks a body's syntax from its Content-Type, falling back to
+// JSON if the body parses as JSON
+func detectSyntax(contentType, body string) int {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		return syntaxJSON
+	case mediaType == "text/html" || mediaType == "application/xhtml+xml":
+		return syntaxHTML
+	case mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml"):
+		return syntaxXML
+	case strings.HasSuffix(mediaType, "yaml"):
+		// application/yaml, application/x-yaml, text/yaml and +yaml types
+		return syntaxYAML
+	}
+	if isJSON(body) {
+		return syntaxJSON
+	}
+	return syntaxNone
+}
+
+// highlight indents and colorizes a body according to its Content-Type. The
+// body's text is sanitized before color codes are added around it.
+func highlight(contentType, body string) string {
+	switch detectSyntax(contentType, body) {
+	case syntaxJSON:
+		return highlightJSON(body)
+	case syntaxXML:
+		return highlightMarkup(body, false)
+	case syntaxHTML:
+		return highlightMarkup(body, true)
+	case syntaxYAML:
+		return highlightYAML(body)
+	}
+	return sanitizeOutput(body)
+}
+
+// highlightJSON indents and colorizes JSON, or a sequence of JSON documents.
+// Anything else is returned sanitized but otherwise unchanged.
+func highlightJSON(s string) string {
+	if !isJSON(s) {
+		return sanitizeOutput(s)
+	}
+	pretty := prettyJSON(s)
+
+	var b strings.Builder
+	for i := 0; i < len(pretty); {
+		c := pretty[i]
+		switch {
+		case c == '"':
+			end := i + 1
+			for end < len(pretty) && pretty[end] != '"' {
+				if pretty[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			end++
+			if end > len(pretty) {
+				end = len(pretty)
+			}
+			str := sanitizeOutput(pretty[i:end])
+			// A string followed by a colon is an object key
+			if strings.HasPrefix(strings.TrimLeft(pretty[end:], " "), ":") {
+				b.WriteString(keyColor.Sprint(str))
+			} else {
+				b.WriteString(stringColor.Sprint(str))
+			}
+			i = end
+		case c == '-' || c >= '0' && c <= '9':
+			end := i + 1
+			for end < len(pretty) && strings.IndexByte("0123456789.eE+-", pretty[end]) >= 0 {
+				end++
+			}
+			b.WriteString(numberColor.Sprint(pretty[i:end]))
+			i = end
+		case c == 't' || c == 'f' || c == 'n':
+			end := i
+			for end < len(pretty) && pretty[end] >= 'a' && pretty[end] <= 'z' {
+				end++
+			}
+			b.WriteString(literalColor.Sprint(pretty[i:end]))
+			i = end
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return b.String()
+}
+
+// isJSON reports whether s holds one or more JSON documents
+func isJSON(s string) bool {
+	decoder := json.NewDecoder(strings.NewReader(s))
+	for n := 0; ; n++ {
+		var doc json.RawMessage
+		if err := decoder.Decode(&doc); err == io.EOF {
+			return n > 0
+		} else if err != nil {
+			return false
+		}
+	}
+}
+
+// markupToken is a piece of an XML or HTML document
+type markupToken struct {
+	kind    int
+	text    string
+	name    string // lowercased tag name, for tags
+	closing bool   // </name>
+	empty   bool   // <name/>
+}
+
+// Kinds of markupToken
+const (
+	markupText    = iota
+	markupTag     // start or end tag
+	markupComment // <!-- -->
+	markupDecl    // <!DOCTYPE>, <?xml?>, <![CDATA[]]>
+	markupRaw     // contents of <script>, <style>, <pre> or <textarea>, kept verbatim
+)
+
+// htmlVoidElements never have an end tag
+var htmlVoidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "source": true, "track": true, "wbr": true,
+}
+
+// htmlRawElements hold text whose whitespace matters or that isn't markup
+var htmlRawElements = map[string]bool{"script": true, "style": true, "pre": true, "textarea": true}
+
+// scanMarkup splits an XML or HTML document into tokens. It reports false if
+// s isn't well-formed enough to tokenize, such as an unterminated tag or a "<"
+// that doesn't start one.
+func scanMarkup(s string, html bool) ([]markupToken, bool) {
+	var tokens []markupToken
+	for i := 0; i < len(s); {
+		if s[i] != '<' {
+			end := strings.IndexByte(s[i:], '<')
+			if end < 0 {
+				end = len(s) - i
+			}
+			tokens = append(tokens, markupToken{kind: markupText, text: s[i : i+end]})
+			i += end
+			continue
+		}
+
+		var tok markupToken
+		var end int
+		switch {
+		case strings.HasPrefix(s[i:], "<!--"):
+			tok.kind, end = markupComment, indexAfter(s[i:], "-->")
+		case strings.HasPrefix(s[i:], "<![CDATA["):
+			tok.kind, end = markupDecl, indexAfter(s[i:], "]]>")
+		case strings.HasPrefix(s[i:], "<!"), strings.HasPrefix(s[i:], "<?"):
+			tok.kind, end = markupDecl, indexAfter(s[i:], ">")
+		default:
+			tok.kind, end = markupTag, tagEnd(s[i:])
+		}
+		if end < 0 {
+			return nil, false
+		}
+		tok.text = s[i : i+end]
+		i += end
+
+		if tok.kind == markupTag {
+			name := strings.TrimPrefix(tok.text[1:], "/")
+			if n := strings.IndexAny(name, " \t\r\n/>"); n >= 0 {
+				name = name[:n]
+			}
+			if !isMarkupName(name) {
+				return nil, false
+			}
+			tok.name = strings.ToLower(name)
+			tok.closing = strings.HasPrefix(tok.text, "</")
+			tok.empty = strings.HasSuffix(tok.text, "/>") || html && htmlVoidElements[tok.name]
+		}
+		tokens = append(tokens, tok)
+
+		// Keep the contents of raw HTML elements as they are
+		if html && tok.kind == markupTag && !tok.closing && !tok.empty && htmlRawElements[tok.name] {
+			end := strings.Index(strings.ToLower(s[i:]), "</"+tok.name)
+			if end < 0 {
+				end = len(s) - i
+			}
+			if end > 0 {
+				tokens = append(tokens, markupToken{kind: markupRaw, text: s[i : i+end]})
+			}
+			i += end
+		}
+	}
+	return tokens, true
+}
+
+// isMarkupName reports whether name can be an element name: it starts with a
+// letter, "_" or ":" (or any non-ASCII character)
+func isMarkupName(name string) bool {
+	if name == "" {
+		return false
+	}
+	c := name[0]
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || c == ':' || c >= 0x80
+}
+
+// indexAfter returns the index just past the first sep in s, or -1
+func indexAfter(s, sep string) int {
+	if i := strings.Index(s, sep); i >= 0 {
+		return i + len(sep)
+	}
+	return -1
+}
+
+// tagEnd returns the index just past the ">" ending the tag at the start of
+// s, skipping quoted attribute values, or -1
+func tagEnd(s string) int {
+	var quote byte
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '>':
+			return i + 1
+		}
+	}
+	return -1
+}
+
+// highlightMarkup colorizes XML or HTML. Documents on a single line are
+// indented one element per line; others keep their own layout. Text that
+// can't be tokenized as markup is shown unchanged.
+func highlightMarkup(s string, html bool) string {
+	tokens, ok := scanMarkup(s, html)
+	if !ok {
+		// Reformatting text that isn't really markup would change what it says
+		return sanitizeOutput(s)
+	}
+	if strings.Contains(strings.TrimSpace(s), "\n") {
+		var b strings.Builder
+		for _, tok := range tokens {
+			b.WriteString(highlightMarkupToken(tok))
+		}
+		return b.String()
+	}
+
+	var lines []string
+	depth := 0
+	emit := func(text string) {
+		lines = append(lines, strings.Repeat("  ", depth)+text)
+	}
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		switch {
+		case tok.kind == markupText:
+			if text := strings.TrimSpace(tok.text); text != "" {
+				emit(sanitizeOutput(text))
+			}
+		case tok.kind == markupRaw:
+			lines = append(lines, sanitizeOutput(strings.Trim(tok.text, "\r\n")))
+		case tok.kind != markupTag || tok.empty:
+			emit(highlightMarkupToken(tok))
+		case tok.closing:
+			if depth > 0 {
+				depth--
+			}
+			emit(highlightMarkupToken(tok))
+		default:
+			// Keep <a>text</a> and <a></a> on one line
+			if n, ok := inlineElement(tokens, i); ok {
+				var b strings.Builder
+				for _, t := range tokens[i : i+n] {
+					b.WriteString(highlightMarkupToken(t))
+				}
+				emit(b.String())
+				i += n - 1
+				continue
+			}
+			emit(highlightMarkupToken(tok))
+			depth++
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// inlineElement reports whether the start tag at tokens[i] is closed right
+// away or after a single piece of text, returning the number of tokens
+func inlineElement(tokens []markupToken, i int) (int, bool) {
+	closes := func(j int) bool {
+		return j < len(tokens) && tokens[j].kind == markupTag && tokens[j].closing && tokens[j].name == tokens[i].name
+	}
+	if closes(i + 1) {
+		return 2, true
+	}
+	if i+1 < len(tokens) && (tokens[i+1].kind == markupText || tokens[i+1].kind == markupRaw) && closes(i+2) {
+		return 3, true
+	}
+	return 0, false
+}
+
+// highlightMarkupToken colorizes one token, keeping its text as it is
+func highlightMarkupToken(tok markupToken) string {
+	switch tok.kind {
+	case markupTag:
+		return highlightTag(tok.text)
+	case markupComment, markupDecl:
+		return commentColor.Sprint(sanitizeOutput(tok.text))
+	}
+	return sanitizeOutput(tok.text)
+}
+
+// highlightTag colors a tag's name, attribute names and attribute values
+func highlightTag(tag string) string {
+	var b strings.Builder
+
+	// "<" or "</" and the name
+	i := 1
+	if strings.HasPrefix(tag, "</") {
+		i = 2
+	}
+	for i < len(tag) && !strings.ContainsRune(" \t\r\n/>", rune(tag[i])) {
+		i++
+	}
+	b.WriteString(tagColor.Sprint(sanitizeOutput(tag[:i])))
+
+	for i < len(tag) {
+		c := tag[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			b.WriteByte(c)
+			i++
+		case c == '/' || c == '>':
+			b.WriteString(tagColor.Sprint(tag[i:]))
+			return b.String()
+		default:
+			// name, optionally followed by =value
+			start := i
+			for i < len(tag) && !strings.ContainsRune(" \t\r\n=/>", rune(tag[i])) {
+				i++
+			}
+			b.WriteString(attrColor.Sprint(sanitizeOutput(tag[start:i])))
+			if i < len(tag) && tag[i] == '=' {
+				b.WriteByte('=')
+				i++
+				start = i
+				if i < len(tag) && (tag[i] == '"' || tag[i] == '\'') {
+					if end := strings.IndexByte(tag[i+1:], tag[i]); end >= 0 {
+						i += end + 2
+					} else {
+						i = len(tag)
+					}
+				} else {
+					for i < len(tag) && !strings.ContainsRune(" \t\r\n>", rune(tag[i])) {
+						i++
+					}
+				}
+				b.WriteString(stringColor.Sprint(sanitizeOutput(tag[start:i])))
+			}
+		}
+	}
+	return b.String()
+}
+
+var (
+	yamlKeyPattern    = regexp.MustCompile(`^(\s*(?:- +)*)("[^"]*"|'[^']*'|[^\s#'"{\[][^:#]*?)(:)(\s+|$)(.*)$`)
+	yamlItemPattern   = regexp.MustCompile(`^(\s*(?:- +)+)(.*)$`)
+	yamlNumberPattern = regexp.MustCompile(`^[-+]?(\.?[0-9][0-9_]*(\.[0-9_]*)?([eE][-+]?[0-9]+)?|0x[0-9a-fA-F]+|\.inf|\.nan)$`)
+	yamlLiterals      = map[string]bool{"true": true, "false": true, "null": true, "~": true, "yes": true, "no": true, "on": true, "off": true}
+)
+
+// highlightYAML colorizes YAML line by line: keys, scalars by type, and comments
+func highlightYAML(s string) string {
+	lines := strings.Split(s, "\n")
+	blockIndent := -1 // lines indented deeper than this belong to a | or > block
+	for i, line := range lines {
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		trimmed := strings.TrimSpace(line)
+
+		if blockIndent >= 0 {
+			if trimmed == "" || indent > blockIndent {
+				lines[i] = stringColor.Sprint(sanitizeOutput(line))
+				continue
+			}
+			blockIndent = -1
+		}
+
+		switch {
+		case trimmed == "":
+			lines[i] = sanitizeOutput(line)
+		case strings.HasPrefix(trimmed, "#"), trimmed == "---", trimmed == "...":
+			lines[i] = commentColor.Sprint(sanitizeOutput(line))
+		default:
+			var prefix, key, value string
+			if m := yamlKeyPattern.FindStringSubmatch(line); m != nil {
+				prefix, key, value = m[1], keyColor.Sprint(sanitizeOutput(m[2]))+m[3]+m[4], m[5]
+			} else if m := yamlItemPattern.FindStringSubmatch(line); m != nil {
+				prefix, value = m[1], m[2]
+			} else {
+				prefix, value = line[:indent], line[indent:]
+			}
+			if strings.HasPrefix(value, "|") || strings.HasPrefix(value, ">") {
+				blockIndent = indent
+			}
+			lines[i] = sanitizeOutput(prefix) + key + highlightYAMLValue(value)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// highlightYAMLValue colors a scalar by its type, and a trailing comment
+func highlightYAMLValue(value string) string {
+	comment := ""
+	if !strings.HasPrefix(value, "\"") && !strings.HasPrefix(value, "'") {
+		if i := strings.Index(value, " #"); i >= 0 {
+			value, comment = value[:i], value[i:]
+		}
+	}
+
+	v := strings.TrimSpace(value)
+	out := sanitizeOutput(value)
+	switch {
+	case v == "":
+	case strings.HasPrefix(v, "|"), strings.HasPrefix(v, ">"), strings.HasPrefix(v, "&"),
+		strings.HasPrefix(v, "*"), strings.HasPrefix(v, "!"), strings.HasPrefix(v, "{"), strings.HasPrefix(v, "["):
+		// Block indicators, anchors, aliases, tags and flow collections are left plain
+	case yamlLiterals[strings.ToLower(v)]:
+		out = literalColor.Sprint(out)
+	case yamlNumberPattern.MatchString(v):
+		out = numberColor.Sprint(out)
+	default:
+		out = stringColor.Sprint(out)
+	}
+	if comment != "" {
+		out += commentColor.Sprint(sanitizeOutput(comment))
+	}
+	return out
+}
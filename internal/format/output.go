@@ -4,7 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"sort"
+	"io"
 	"strings"
 	"unicode"
 
@@ -53,22 +53,88 @@ var (
 
 // PrintResponse prints a formatted HTTP response
 func PrintResponse(resp *model.Response, showHeaders bool) {
+	printResponseHead(resp, showHeaders)
+
+	// Downloaded bodies live on disk, so describe the file instead
+	if resp.Download != nil {
+		printDownload(resp.Download)
+		return
+	}
+
+	// Decode legacy charsets for display; resp.Body keeps the original bytes
+	body := DecodeText(resp.ContentType, resp.Body)
+
+	// Binary bodies would be garbled by the terminal, so summarize them instead
+	if IsBinary(resp.ContentType, body) {
+		printBinaryBody(resp.Body, resp.ContentType)
+		return
+	}
+
+	// Print body
+	printBody(body, resp.ContentType)
+}
+
+// PrintResponseHead prints the status line and, if requested, the headers of a
+// response without its body: the response to a HEAD request, or the start of
+// an event stream whose body is printed as it arrives
+func PrintResponseHead(resp *model.Response, showHeaders bool) {
+	printResponseHead(resp, showHeaders)
+	if showHeaders && len(resp.Headers) == 0 {
+		dimColor.Println("(no headers)")
+	}
+}
+
+func printResponseHead(resp *model.Response, showHeaders bool) {
+	// Show how we got here before the final status
+	if showHeaders && len(resp.Redirects) > 0 {
+		printRedirects(resp.Redirects)
+	}
+
 	// Print status line with color based on status code
 	printStatusLine(resp)
 
-	// Print duration
-	dimColor.Printf("  Time: %dms\n\n", resp.DurationMs)
+	// Print duration, and for compressed bodies the size before and after decoding
+	if resp.WireSize > 0 && resp.Download == nil {
+		dimColor.Printf("  Time: %dms  Size: %s (%s on the wire, %s)\n\n", resp.DurationMs,
+			FormatBytes(int64(len(resp.Body))), FormatBytes(resp.WireSize), sanitizeOutput(strings.Join(resp.Headers.Values("Content-Encoding"), ", ")))
+	} else {
+		dimColor.Printf("  Time: %dms\n\n", resp.DurationMs)
+	}
 
 	// Print headers if requested
 	if showHeaders {
 		printHeaders(resp.Headers)
 	}
+}
 
-	// Print body
-	printBody(resp.Body)
+func printDownload(d *model.Download) {
+	successColor.Print("Saved to ")
+	fmt.Println(sanitizeOutput(d.Path))
+	if d.Resumed {
+		dimColor.Printf("  Size: %s (resumed)\n", FormatBytes(d.Size))
+	} else {
+		dimColor.Printf("  Size: %s\n", FormatBytes(d.Size))
+	}
+	dimColor.Printf("  SHA-256: %s\n", d.SHA256)
+}
+
+func printRedirects(redirects []model.Redirect) {
+	fmt.Println("Redirects:")
+	for i, r := range redirects {
+		dimColor.Printf("  [%d] ", i+1)
+		getStatusColor(r.StatusCode).Printf("%s ", sanitizeOutput(r.Status))
+		methodColor.Printf("%s ", r.Method)
+		urlColor.Print(sanitizeOutput(r.URL))
+		fmt.Printf(" → %s ", sanitizeOutput(r.Location))
+		dimColor.Printf("(%dms)\n", r.DurationMs)
+	}
+	fmt.Println()
 }
 
 func printStatusLine(resp *model.Response) {
+	if resp.Protocol != "" {
+		dimColor.Printf("%s ", sanitizeOutput(resp.Protocol))
+	}
 	statusColor := getStatusColor(resp.StatusCode)
 	statusColor.Printf("%s\n", sanitizeOutput(resp.Status))
 }
@@ -86,46 +152,60 @@ func getStatusColor(code int) *color.Color {
 	}
 }
 
-func printHeaders(headers map[string]string) {
+func printHeaders(headers model.Headers) {
 	if len(headers) == 0 {
 		return
 	}
 
 	fmt.Println("Headers:")
 
-	// Sort headers for consistent output
-	keys := make([]string, 0, len(headers))
-	for k := range headers {
-		keys = append(keys, k)
-	}
-	sort.Strings(keys)
-
-	for _, key := range keys {
-		headerKeyColor.Printf("  %s: ", sanitizeOutput(key))
-		fmt.Println(sanitizeOutput(headers[key]))
+	// Sort headers for consistent output; repeated headers print one line per value
+	for _, key := range headers.Keys() {
+		for _, value := range headers[key] {
+			headerKeyColor.Printf("  %s: ", sanitizeOutput(key))
+			fmt.Println(sanitizeOutput(value))
+		}
 	}
 	fmt.Println()
 }
 
-func printBody(body string) {
+func printBody(body, contentType string) {
 	if body == "" {
 		dimColor.Println("(empty body)")
 		return
 	}
 
-	// Try to pretty-print JSON, then sanitize output for terminal safety
-	prettyBody := prettyJSON(body)
-	fmt.Println(sanitizeOutput(prettyBody))
+	// Indent and highlight by content type; highlight sanitizes for terminal safety
+	fmt.Println(highlight(contentType, body))
 }
 
 func prettyJSON(s string) string {
 	var out bytes.Buffer
 	err := json.Indent(&out, []byte(s), "", "  ")
-	if err != nil {
-		// Not valid JSON, return as-is
+	if err == nil {
+		return out.String()
+	}
+
+	// A sequence of JSON documents, such as filter results or streamed
+	// messages, is indented one document at a time
+	decoder := json.NewDecoder(strings.NewReader(s))
+	var docs []string
+	for {
+		var doc json.RawMessage
+		if err := decoder.Decode(&doc); err == io.EOF {
+			break
+		} else if err != nil {
+			// Not valid JSON, return as-is
+			return s
+		}
+		out.Reset()
+		json.Indent(&out, doc, "", "  ")
+		docs = append(docs, out.String())
+	}
+	if len(docs) == 0 {
 		return s
 	}
-	return out.String()
+	return strings.Join(docs, "\n")
 }
 
 // PrintRequest prints a formatted HTTP request summary
@@ -149,7 +229,25 @@ func PrintRequestDetail(req *model.Request) {
 	methodColor.Printf("%s ", req.Method)
 	urlColor.Println(sanitizeOutput(req.URL))
 	dimColor.Printf("ID: %s\n", req.ID)
-	dimColor.Printf("Time: %s\n\n", req.Timestamp.Format("2006-01-02 15:04:05"))
+	dimColor.Printf("Time: %s\n", req.Timestamp.Format("2006-01-02 15:04:05"))
+	if req.Pinned {
+		redirectColor.Println("Pinned")
+	}
+	if len(req.Tags) > 0 {
+		dimColor.Print("Tags: ")
+		for i, tag := range req.Tags {
+			if i > 0 {
+				fmt.Print(" ")
+			}
+			headerKeyColor.Printf("#%s", sanitizeOutput(tag))
+		}
+		fmt.Println()
+	}
+	if req.Note != "" {
+		dimColor.Print("Note: ")
+		fmt.Println(sanitizeOutput(req.Note))
+	}
+	fmt.Println()
 
 	if len(req.Headers) > 0 {
 		printHeaders(req.Headers)
@@ -157,19 +255,35 @@ func PrintRequestDetail(req *model.Request) {
 
 	if req.Body != "" {
 		fmt.Println("Body:")
-		fmt.Println(sanitizeOutput(prettyJSON(req.Body)))
+		contentType := req.Headers.Get("Content-Type")
+		body := DecodeText(contentType, req.Body)
+		if IsBinary(contentType, body) {
+			printBinaryBody(req.Body, contentType)
+		} else {
+			fmt.Println(highlight(contentType, body))
+		}
 		fmt.Println()
 	}
 
 	if req.Response != nil {
 		fmt.Println("\nResponse:")
 		fmt.Println(strings.Repeat("-", 40))
-		PrintResponse(req.Response, true)
+		if req.Method == "HEAD" {
+			PrintResponseHead(req.Response, true)
+		} else {
+			PrintResponse(req.Response, true)
+		}
 	}
 }
 
-// PrintHistoryList prints a list of requests in a compact format
-func PrintHistoryList(requests []model.Request, limit int) {
+// PrintHistoryList prints a list of requests in a compact format. positions
+// holds each request's number in the full history when the list is filtered;
+// if nil, requests are numbered from 1.
+func PrintHistoryList(requests []model.Request, positions []int, limit int) {
+	if len(requests) == 0 && positions != nil {
+		dimColor.Println("No matching requests in history")
+		return
+	}
 	if len(requests) == 0 {
 		dimColor.Println("No requests in history")
 		return
@@ -182,7 +296,7 @@ func PrintHistoryList(requests []model.Request, limit int) {
 
 	for i := 0; i < count; i++ {
 		req := requests[i]
-		dimColor.Printf("[%d] ", i+1)
+		dimColor.Printf("[%d] ", historyPosition(positions, i))
 		methodColor.Printf("%-7s ", req.Method)
 
 		// Truncate URL if too long, then sanitize
@@ -197,6 +311,12 @@ func PrintHistoryList(requests []model.Request, limit int) {
 			statusColor.Printf("%d ", req.Response.StatusCode)
 			dimColor.Printf("(%dms)", req.Response.DurationMs)
 		}
+		if req.Pinned {
+			redirectColor.Print(" pinned")
+		}
+		for _, tag := range req.Tags {
+			headerKeyColor.Printf(" #%s", sanitizeOutput(tag))
+		}
 		fmt.Println()
 	}
 
@@ -205,6 +325,51 @@ func PrintHistoryList(requests []model.Request, limit int) {
 	}
 }
 
+// historyPosition returns the number shown for the i'th listed request
+func historyPosition(positions []int, i int) int {
+	if positions == nil {
+		return i + 1
+	}
+	return positions[i]
+}
+
+// PrintHistoryStats prints request counts, error rates, latency percentiles and
+// response sizes per host, endpoint and method
+func PrintHistoryStats(stats []model.EndpointStats) {
+	if len(stats) == 0 {
+		dimColor.Println("No requests in history")
+		return
+	}
+
+	methodWidth, hostWidth, endpointWidth := len("METHOD")+1, len("HOST"), len("ENDPOINT")
+	for _, st := range stats {
+		methodWidth = max(methodWidth, len(st.Method))
+		hostWidth = max(hostWidth, len(sanitizeOutput(st.Host)))
+		endpointWidth = max(endpointWidth, len(sanitizeOutput(st.Endpoint)))
+	}
+
+	dimColor.Printf("%-*s %-*s %-*s %6s %7s %8s %8s %8s %9s %9s\n",
+		methodWidth, "METHOD", hostWidth, "HOST", endpointWidth, "ENDPOINT",
+		"COUNT", "ERRORS", "P50", "P95", "P99", "AVG SIZE", "MAX SIZE")
+	for _, st := range stats {
+		methodColor.Printf("%-*s ", methodWidth, st.Method)
+		fmt.Printf("%-*s ", hostWidth, sanitizeOutput(st.Host))
+		urlColor.Printf("%-*s ", endpointWidth, sanitizeOutput(st.Endpoint))
+		fmt.Printf("%6d ", st.Count)
+
+		errorRate := fmt.Sprintf("%.1f%%", st.ErrorRate()*100)
+		if st.Errors > 0 {
+			clientErrColor.Printf("%7s ", errorRate)
+		} else {
+			fmt.Printf("%7s ", errorRate)
+		}
+
+		fmt.Printf("%8s %8s %8s %9s %9s\n",
+			fmt.Sprintf("%dms", st.P50Ms), fmt.Sprintf("%dms", st.P95Ms), fmt.Sprintf("%dms", st.P99Ms),
+			FormatBytes(st.AvgSize), FormatBytes(st.MaxSize))
+	}
+}
+
 // PrintCollectionList prints a list of collections
 func PrintCollectionList(collections *model.Collections) {
 	if len(collections.Collections) == 0 {
@@ -234,8 +399,22 @@ func PrintCollectionRequests(col *model.Collection) {
 		if req.Name != "" {
 			fmt.Printf("%s: ", sanitizeOutput(req.Name))
 		}
+		if req.RequestKind() == model.RequestKindGraphQL {
+			printSavedGraphQLRequest(req)
+			continue
+		}
 		methodColor.Printf("%s ", req.Method)
 		urlColor.Println(sanitizeOutput(req.URL))
+		PrintQueryParams(req.Query, "      ")
+	}
+}
+
+// PrintQueryParams prints query parameters one per line, unencoded
+func PrintQueryParams(query []model.QueryParam, indent string) {
+	for _, p := range query {
+		headerKeyColor.Printf("%s%s", indent, sanitizeOutput(p.Key))
+		dimColor.Print(" = ")
+		fmt.Println(sanitizeOutput(p.Value))
 	}
 }
 
@@ -270,3 +449,99 @@ func PrintAlias(name, url string) {
 	dimColor.Print("→ ")
 	urlColor.Println(sanitizeOutput(url))
 }
+
+// PrintSecretList prints the names of stored secrets (never their values)
+func PrintSecretList(names []string) {
+	if len(names) == 0 {
+		dimColor.Println("No secrets found")
+		return
+	}
+
+	fmt.Println("Secrets:")
+	for _, name := range names {
+		headerKeyColor.Printf("  %s\n", sanitizeOutput(name))
+	}
+}
+
+// PrintSessionList prints sessions with their cookie counts
+func PrintSessionList(sessions []model.Session) {
+	if len(sessions) == 0 {
+		dimColor.Println("No sessions found")
+		return
+	}
+
+	fmt.Println("Sessions:")
+	for _, s := range sessions {
+		headerKeyColor.Printf("  %s ", sanitizeOutput(s.Name))
+		dimColor.Printf("(%d cookies, updated %s)\n", len(s.Cookies), s.UpdatedAt.Format("2006-01-02 15:04:05"))
+	}
+}
+
+// PrintSession prints the cookies in a session. Values are hidden unless showValues is set.
+func PrintSession(session *model.Session, showValues bool) {
+	headerKeyColor.Printf("Session: %s\n", sanitizeOutput(session.Name))
+	fmt.Println(strings.Repeat("-", 40))
+
+	if len(session.Cookies) == 0 {
+		dimColor.Println("No cookies")
+		return
+	}
+
+	for _, c := range session.Cookies {
+		value := "[REDACTED]"
+		if showValues {
+			value = c.Value
+		}
+		headerKeyColor.Printf("%s", sanitizeOutput(c.Name))
+		fmt.Printf("=%s\n", sanitizeOutput(value))
+
+		domain := c.Domain
+		if !c.HostOnly {
+			domain = "." + domain
+		}
+		attrs := []string{"Domain: " + domain, "Path: " + c.Path}
+		if c.Expires.IsZero() {
+			attrs = append(attrs, "Expires: session")
+		} else {
+			attrs = append(attrs, "Expires: "+c.Expires.Format("2006-01-02 15:04:05"))
+		}
+		if c.Secure {
+			attrs = append(attrs, "Secure")
+		}
+		if c.HttpOnly {
+			attrs = append(attrs, "HttpOnly")
+		}
+		dimColor.Printf("  %s\n", sanitizeOutput(strings.Join(attrs, "  ")))
+	}
+}
+
+// PrintEvent prints a server-sent event as it arrives
+func PrintEvent(event model.Event) {
+	headerKeyColor.Printf("event: %s", sanitizeOutput(event.Type))
+	if event.ID != "" {
+		dimColor.Printf("  id: %s", sanitizeOutput(event.ID))
+	}
+	fmt.Println()
+	fmt.Println(highlightJSON(event.Data))
+	fmt.Println()
+}
+
+// PrintStreamNotice prints a status message about an event stream (e.g. a reconnect)
+func PrintStreamNotice(msg string) {
+	dimColor.Printf("(%s)\n", msg)
+}
+
+// PrintWebSocketMessage prints a WebSocket message that was sent or received
+func PrintWebSocketMessage(sent bool, data string, binary bool) {
+	if sent {
+		methodColor.Print("→ ")
+	} else {
+		successColor.Print("← ")
+	}
+
+	if binary || IsBinary("", data) {
+		dimColor.Printf("(binary message, %s)\n", FormatBytes(int64(len(data))))
+		return
+	}
+	fmt.Println(highlightJSON(data))
+}
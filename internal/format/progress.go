@@ -0,0 +1,146 @@
+package format
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+)
+
+const (
+	// progressBarWidth is the number of characters in the bar itself
+	progressBarWidth = 30
+
+	// progressInterval limits how often the progress line is redrawn
+	progressInterval = 100 * time.Millisecond
+)
+
+// progressReader wraps a reader and draws a progress line on stderr as it is read
+type progressReader struct {
+	r        io.Reader
+	label    string
+	total    int64
+	current  int64
+	start    time.Time
+	lastDraw time.Time
+	finished bool
+}
+
+// NewProgressReader wraps r so that reading from it draws a progress bar on
+// stderr. total is the expected size, or -1 if unknown. When stderr is not a
+// terminal, r is returned unchanged.
+func NewProgressReader(r io.Reader, label string, total int64) io.Reader {
+	if !term.IsTerminal(int(os.Stderr.Fd())) {
+		return r
+	}
+	return &progressReader{r: r, label: label, total: total, start: time.Now()}
+}
+
+// NewProgressWriter wraps w so that writing to it draws a progress bar on
+// stderr. start is the number of bytes already present (e.g. when resuming).
+// When stderr is not a terminal, w is returned unchanged.
+func NewProgressWriter(w io.Writer, label string, start, total int64) io.Writer {
+	if !term.IsTerminal(int(os.Stderr.Fd())) {
+		return w
+	}
+	return &progressWriter{w: w, p: progressReader{label: label, total: total, current: start, start: time.Now()}}
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.advance(n, err == io.EOF)
+	return n, err
+}
+
+// Close finishes the progress line and closes the underlying reader if possible
+func (p *progressReader) Close() error {
+	p.finish()
+	if closer, ok := p.r.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// advance records n more bytes and redraws the line if enough time has passed
+func (p *progressReader) advance(n int, eof bool) {
+	p.current += int64(n)
+	if eof {
+		p.finish()
+		return
+	}
+	if time.Since(p.lastDraw) >= progressInterval {
+		p.draw()
+	}
+}
+
+// finish draws the final state and ends the progress line
+func (p *progressReader) finish() {
+	if p.finished {
+		return
+	}
+	p.finished = true
+	p.draw()
+	fmt.Fprintln(os.Stderr)
+}
+
+func (p *progressReader) draw() {
+	p.lastDraw = time.Now()
+
+	rate := ""
+	if elapsed := time.Since(p.start).Seconds(); elapsed > 0 {
+		rate = fmt.Sprintf("  %s/s", FormatBytes(int64(float64(p.current)/elapsed)))
+	}
+
+	if p.total <= 0 {
+		fmt.Fprintf(os.Stderr, "\r  %s %s%s\033[K", p.label, FormatBytes(p.current), rate)
+		return
+	}
+
+	ratio := float64(p.current) / float64(p.total)
+	if ratio > 1 {
+		ratio = 1
+	}
+	filled := int(ratio * progressBarWidth)
+	bar := strings.Repeat("=", filled)
+	if filled < progressBarWidth {
+		bar += ">" + strings.Repeat(" ", progressBarWidth-filled-1)
+	}
+
+	fmt.Fprintf(os.Stderr, "\r  %s [%s] %3.0f%% %s/%s%s\033[K",
+		p.label, bar, ratio*100, FormatBytes(p.current), FormatBytes(p.total), rate)
+}
+
+// progressWriter draws a progress bar for data written through it
+type progressWriter struct {
+	w io.Writer
+	p progressReader
+}
+
+func (pw *progressWriter) Write(b []byte) (int, error) {
+	n, err := pw.w.Write(b)
+	pw.p.advance(n, false)
+	return n, err
+}
+
+// Close finishes the progress line
+func (pw *progressWriter) Close() error {
+	pw.p.finish()
+	return nil
+}
+
+// FormatBytes formats a byte count in human-readable units (e.g. "1.5 MB")
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,429 @@
+package format
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+
+	"api/internal/model"
+)
+
+// Output formats for --output
+const (
+	OutputRaw      = "raw"      // response body only; lists as tab-separated rows
+	OutputJSON     = "json"     // the full model value as JSON
+	OutputYAML     = "yaml"     // the full model value as YAML
+	OutputTable    = "table"    // aligned columns
+	OutputTemplate = "template" // a Go template, run once per list item
+)
+
+// Output selects how results are printed: the default colored output, or one
+// of the formats scripts can consume
+type Output struct {
+	kind     string
+	template *template.Template
+}
+
+// ParseOutput parses an --output value: raw, json, yaml, table or
+// template=<go-template>. An empty value selects the default output.
+func ParseOutput(s string) (Output, error) {
+	kind, text, hasTemplate := strings.Cut(s, "=")
+	switch kind {
+	case "", OutputRaw, OutputJSON, OutputYAML, OutputTable:
+		if hasTemplate {
+			return Output{}, fmt.Errorf("output format %q takes no argument", kind)
+		}
+		return Output{kind: kind}, nil
+	case OutputTemplate:
+		if text == "" {
+			return Output{}, fmt.Errorf("missing template (use --output 'template={{.Status}}')")
+		}
+		tmpl, err := template.New("output").Funcs(template.FuncMap{
+			"json": func(v interface{}) (string, error) {
+				data, err := json.Marshal(v)
+				return string(data), err
+			},
+		}).Parse(text)
+		if err != nil {
+			return Output{}, fmt.Errorf("invalid template: %v", err)
+		}
+		return Output{kind: kind, template: tmpl}, nil
+	}
+	return Output{}, fmt.Errorf("unknown output format %q (expected raw, json, yaml, table or template=...)", s)
+}
+
+// IsDefault reports whether the default colored output was chosen
+func (o Output) IsDefault() bool {
+	return o.kind == ""
+}
+
+// IsRaw reports whether only raw bodies should be printed
+func (o Output) IsRaw() bool {
+	return o.kind == OutputRaw
+}
+
+// Response prints a response. JSON and YAML hold the whole response: status,
+// headers, timing and body, decoded to UTF-8; raw output keeps the original bytes.
+func (o Output) Response(resp *model.Response) error {
+	if o.IsRaw() {
+		return PrintRaw(resp)
+	}
+	return o.write(decodedResponse(resp), nil, responseTable(resp, nil))
+}
+
+// Request prints a history entry; raw output is its response body
+func (o Output) Request(req *model.Request) error {
+	if o.IsRaw() {
+		if req.Response == nil {
+			return fmt.Errorf("request has no stored response")
+		}
+		return PrintRaw(req.Response)
+	}
+
+	t := table{header: []string{"FIELD", "VALUE"}}
+	t.rows = append(t.rows,
+		[]string{"ID", req.ID},
+		[]string{"Time", req.Timestamp.Format("2006-01-02 15:04:05")},
+		[]string{"Method", req.Method},
+		[]string{"URL", req.URL},
+	)
+	if req.Pinned {
+		t.rows = append(t.rows, []string{"Pinned", "yes"})
+	}
+	if len(req.Tags) > 0 {
+		t.rows = append(t.rows, []string{"Tags", strings.Join(req.Tags, ",")})
+	}
+	if req.Note != "" {
+		t.rows = append(t.rows, []string{"Note", req.Note})
+	}
+	if req.Response != nil {
+		t = responseTable(req.Response, t.rows)
+	}
+	return o.write(decodedRequest(req), nil, t)
+}
+
+// decodedRequest returns req with its response body ready for text output
+func decodedRequest(req *model.Request) *model.Request {
+	if req.Response == nil {
+		return req
+	}
+	decoded := *req
+	decoded.Response = decodedResponse(req.Response)
+	return &decoded
+}
+
+// decodedResponse returns resp with its body ready for text output: decoded
+// to UTF-8, or base64-encoded if it is binary, since JSON and YAML strings
+// can't hold arbitrary bytes
+func decodedResponse(resp *model.Response) *model.Response {
+	decoded := *resp
+	if resp.Body != "" && IsBinary(resp.ContentType, resp.Body) {
+		decoded.Body = base64.StdEncoding.EncodeToString([]byte(resp.Body))
+		decoded.BodyEncoding = "base64"
+		return &decoded
+	}
+	decoded.Body = DecodeText(resp.ContentType, resp.Body)
+	return &decoded
+}
+
+// History prints up to limit history entries (all of them if limit is 0),
+// numbered by positions as for PrintHistoryList
+func (o Output) History(requests []model.Request, positions []int, limit int) error {
+	if limit > 0 && limit < len(requests) {
+		requests = requests[:limit]
+	}
+
+	t := table{header: []string{"#", "ID", "METHOD", "URL", "STATUS", "TIME", "DATE", "PINNED", "TAGS"}}
+	items := make([]interface{}, len(requests))
+	decoded := make([]*model.Request, len(requests))
+	for i := range requests {
+		req := &requests[i]
+		decoded[i] = decodedRequest(req)
+		items[i] = decoded[i]
+		status, duration := "", ""
+		if req.Response != nil {
+			status = strconv.Itoa(req.Response.StatusCode)
+			duration = fmt.Sprintf("%dms", req.Response.DurationMs)
+		}
+		pinned := ""
+		if req.Pinned {
+			pinned = "yes"
+		}
+		t.rows = append(t.rows, []string{
+			strconv.Itoa(historyPosition(positions, i)), req.ID, req.Method, req.URL, status, duration,
+			req.Timestamp.Format("2006-01-02 15:04:05"), pinned, strings.Join(req.Tags, ","),
+		})
+	}
+	return o.write(decoded, items, t)
+}
+
+// HistoryStats prints per-endpoint statistics; templates run once per endpoint
+func (o Output) HistoryStats(stats []model.EndpointStats) error {
+	t := table{header: []string{"METHOD", "HOST", "ENDPOINT", "COUNT", "ERRORS", "ERROR RATE", "P50", "P95", "P99", "AVG SIZE", "MAX SIZE"}}
+	items := make([]interface{}, len(stats))
+	for i := range stats {
+		st := &stats[i]
+		items[i] = st
+		t.rows = append(t.rows, []string{
+			st.Method, st.Host, st.Endpoint, strconv.Itoa(st.Count), strconv.Itoa(st.Errors),
+			fmt.Sprintf("%.1f%%", st.ErrorRate()*100),
+			fmt.Sprintf("%dms", st.P50Ms), fmt.Sprintf("%dms", st.P95Ms), fmt.Sprintf("%dms", st.P99Ms),
+			FormatBytes(st.AvgSize), FormatBytes(st.MaxSize),
+		})
+	}
+	if stats == nil {
+		stats = []model.EndpointStats{}
+	}
+	return o.write(stats, items, t)
+}
+
+// Collections prints the collections, sorted by name
+func (o Output) Collections(collections *model.Collections) error {
+	names := make([]string, 0, len(collections.Collections))
+	for name := range collections.Collections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	list := make([]model.Collection, 0, len(names))
+	t := table{header: []string{"NAME", "REQUESTS"}}
+	for _, name := range names {
+		col := collections.Collections[name]
+		list = append(list, col)
+		t.rows = append(t.rows, []string{name, strconv.Itoa(len(col.Requests))})
+	}
+
+	items := make([]interface{}, len(list))
+	for i := range list {
+		items[i] = &list[i]
+	}
+	return o.write(list, items, t)
+}
+
+// Collection prints the requests saved in a collection
+func (o Output) Collection(col *model.Collection) error {
+	t := table{header: []string{"#", "NAME", "KIND", "METHOD", "URL"}}
+	items := make([]interface{}, len(col.Requests))
+	for i := range col.Requests {
+		req := &col.Requests[i]
+		items[i] = req
+		t.rows = append(t.rows, []string{strconv.Itoa(i + 1), req.Name, req.RequestKind(), req.Method, req.URL})
+	}
+	shown := *col
+	if shown.Requests == nil {
+		shown.Requests = []model.SavedRequest{}
+	}
+	return o.write(&shown, items, t)
+}
+
+// Aliases prints the aliases, sorted by name. Templates see each alias as
+// .Name and .URL.
+func (o Output) Aliases(aliases *model.Aliases) error {
+	names := make([]string, 0, len(aliases.Aliases))
+	for name := range aliases.Aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	t := table{header: []string{"NAME", "URL"}}
+	items := make([]interface{}, len(names))
+	for i, name := range names {
+		items[i] = struct{ Name, URL string }{name, aliases.Aliases[name]}
+		t.rows = append(t.rows, []string{name, aliases.Aliases[name]})
+	}
+	all := aliases.Aliases
+	if all == nil {
+		all = map[string]string{}
+	}
+	return o.write(all, items, t)
+}
+
+// write prints v as JSON or YAML, t as a table or tab-separated rows, or runs
+// the template on each item (or on v itself when there are no items)
+func (o Output) write(v interface{}, items []interface{}, t table) error {
+	switch o.kind {
+	case OutputJSON:
+		return writeJSON(os.Stdout, v)
+	case OutputYAML:
+		return writeYAML(os.Stdout, v)
+	case OutputTable:
+		return t.write(os.Stdout, true)
+	case OutputRaw:
+		return t.write(os.Stdout, false)
+	case OutputTemplate:
+		if items == nil {
+			items = []interface{}{v}
+		}
+		for _, item := range items {
+			var buf bytes.Buffer
+			if err := o.template.Execute(&buf, item); err != nil {
+				return fmt.Errorf("template failed: %v", err)
+			}
+			out := sanitizeOutput(buf.String())
+			if !strings.HasSuffix(out, "\n") {
+				out += "\n"
+			}
+			if _, err := io.WriteString(os.Stdout, out); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("no structured output selected")
+}
+
+// responseTable lists a response's status, timing, size and headers after rows
+func responseTable(resp *model.Response, rows [][]string) table {
+	t := table{header: []string{"FIELD", "VALUE"}, rows: rows}
+	t.rows = append(t.rows,
+		[]string{"Status", resp.Status},
+		[]string{"Protocol", resp.Protocol},
+		[]string{"Duration", fmt.Sprintf("%dms", resp.DurationMs)},
+	)
+	if resp.Download != nil {
+		t.rows = append(t.rows,
+			[]string{"Saved To", resp.Download.Path},
+			[]string{"Size", FormatBytes(resp.Download.Size)},
+		)
+	} else {
+		t.rows = append(t.rows, []string{"Size", FormatBytes(int64(len(resp.Body)))})
+	}
+	if resp.WireSize > 0 {
+		t.rows = append(t.rows, []string{"Wire Size", FormatBytes(resp.WireSize)})
+	}
+	for _, key := range resp.Headers.Keys() {
+		for _, value := range resp.Headers[key] {
+			t.rows = append(t.rows, []string{key, value})
+		}
+	}
+	return t
+}
+
+// table is rows of cells under a header
+type table struct {
+	header []string
+	rows   [][]string
+}
+
+// write prints the table aligned under its header, or as plain tab-separated
+// rows for scripts
+func (t table) write(w io.Writer, aligned bool) error {
+	cell := func(s string) string {
+		// Keep each row on one line and each cell in one column
+		s = strings.NewReplacer("\t", " ", "\n", " ", "\r", " ").Replace(s)
+		return sanitizeOutput(s)
+	}
+
+	if !aligned {
+		for _, row := range t.rows {
+			cells := make([]string, len(row))
+			for i, c := range row {
+				cells[i] = cell(c)
+			}
+			if _, err := fmt.Fprintln(w, strings.Join(cells, "\t")); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(t.header, "\t"))
+	for _, row := range t.rows {
+		cells := make([]string, len(row))
+		for i, c := range row {
+			cells[i] = cell(c)
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// writeYAML prints v as YAML with the same field names and order as its JSON
+func writeYAML(w io.Writer, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	node, err := yamlNode(decoder)
+	if err != nil {
+		return err
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(node); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// yamlNode converts the next JSON value from decoder to a YAML node, keeping
+// the order of object keys
+func yamlNode(decoder *json.Decoder) (*yaml.Node, error) {
+	tok, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := tok.(type) {
+	case json.Delim:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		if t == '{' {
+			node = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		}
+		for decoder.More() {
+			if t == '{' {
+				key, err := decoder.Token()
+				if err != nil {
+					return nil, err
+				}
+				node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key.(string)})
+			}
+			child, err := yamlNode(decoder)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, child)
+		}
+		// Consume the closing delimiter
+		if _, err := decoder.Token(); err != nil {
+			return nil, err
+		}
+		return node, nil
+	case string:
+		node := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: t}
+		if strings.Contains(t, "\n") {
+			node.Style = yaml.LiteralStyle
+		}
+		return node, nil
+	case json.Number:
+		tag := "!!int"
+		if strings.ContainsAny(t.String(), ".eE") {
+			tag = "!!float"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: t.String()}, nil
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(t)}, nil
+	}
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}, nil
+}
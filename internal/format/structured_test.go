@@ -0,0 +1,68 @@
+package format
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"api/internal/model"
+)
+
+// A PNG signature followed by bytes that are not valid UTF-8
+const binaryBody = "\x89PNG\r\n\x1a\n\x00\xff\xfe\x80\xc3"
+
+func TestBinaryBodyRoundTripsAsBase64(t *testing.T) {
+	resp := &model.Response{StatusCode: 200, ContentType: "image/png", Body: binaryBody}
+
+	var buf bytes.Buffer
+	if err := writeJSON(&buf, decodedResponse(resp)); err != nil {
+		t.Fatal(err)
+	}
+	var fromJSON model.Response
+	if err := json.Unmarshal(buf.Bytes(), &fromJSON); err != nil {
+		t.Fatal(err)
+	}
+	checkBase64Body(t, "json", fromJSON)
+
+	buf.Reset()
+	if err := writeYAML(&buf, decodedResponse(resp)); err != nil {
+		t.Fatal(err)
+	}
+	var fromYAML struct {
+		Body         string `yaml:"body"`
+		BodyEncoding string `yaml:"body_encoding"`
+	}
+	if err := yaml.Unmarshal(buf.Bytes(), &fromYAML); err != nil {
+		t.Fatal(err)
+	}
+	checkBase64Body(t, "yaml", model.Response{Body: fromYAML.Body, BodyEncoding: fromYAML.BodyEncoding})
+
+	if resp.Body != binaryBody {
+		t.Errorf("original response body was modified")
+	}
+}
+
+func checkBase64Body(t *testing.T, format string, resp model.Response) {
+	t.Helper()
+	if resp.BodyEncoding != "base64" {
+		t.Fatalf("%s: body_encoding = %q, want base64", format, resp.BodyEncoding)
+	}
+	body, err := base64.StdEncoding.DecodeString(resp.Body)
+	if err != nil {
+		t.Fatalf("%s: body is not base64: %v", format, err)
+	}
+	if string(body) != binaryBody {
+		t.Errorf("%s: body = %q, want %q", format, body, binaryBody)
+	}
+}
+
+func TestTextBodyIsNotEncoded(t *testing.T) {
+	resp := &model.Response{ContentType: "application/json", Body: `{"name":"café"}`}
+	decoded := decodedResponse(resp)
+	if decoded.BodyEncoding != "" || decoded.Body != resp.Body {
+		t.Errorf("text body changed: %q (encoding %q)", decoded.Body, decoded.BodyEncoding)
+	}
+}
@@ -0,0 +1,131 @@
+package format
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fatih/color"
+	"golang.org/x/term"
+)
+
+// DefaultPager is used when $PAGER is unset
+const DefaultPager = "less -R"
+
+// PagerEnabled lets Page start a pager; --no-pager turns it off
+var PagerEnabled = true
+
+// ansiPattern matches the color codes added by highlighting
+var ansiPattern = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// SetColorMode sets when output is colored: "always", "never", or "auto",
+// which colors only when stdout is a terminal and NO_COLOR is unset
+func SetColorMode(mode string) error {
+	switch mode {
+	case "always":
+		color.NoColor = false
+	case "never":
+		color.NoColor = true
+	case "auto", "":
+		color.NoColor = os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" || !term.IsTerminal(int(os.Stdout.Fd()))
+	default:
+		return fmt.Errorf("invalid color mode %q (expected always, never or auto)", mode)
+	}
+	return nil
+}
+
+// Page runs print, sending what it prints through $PAGER (less -R by
+// default) if stdout is a terminal and the output is taller than it.
+// Setting PAGER to an empty string or "cat" turns paging off.
+func Page(print func()) {
+	pager, ok := os.LookupEnv("PAGER")
+	if !ok {
+		pager = DefaultPager
+	}
+	fd := int(os.Stdout.Fd())
+	if !PagerEnabled || strings.TrimSpace(pager) == "" || strings.TrimSpace(pager) == "cat" || !term.IsTerminal(fd) {
+		print()
+		return
+	}
+	width, height, err := term.GetSize(fd)
+	if err != nil {
+		print()
+		return
+	}
+
+	output := capture(print)
+	if displayLines(output, width) < height {
+		os.Stdout.Write(output)
+		return
+	}
+	if err := runPager(pager, output); err != nil {
+		os.Stdout.Write(output)
+	}
+}
+
+// capture collects what print writes to stdout, including colored output
+func capture(print func()) []byte {
+	r, w, err := os.Pipe()
+	if err != nil {
+		print()
+		return nil
+	}
+
+	var buf bytes.Buffer
+	done := make(chan struct{})
+	go func() {
+		io.Copy(&buf, r)
+		close(done)
+	}()
+
+	stdout, colorOutput := os.Stdout, color.Output
+	os.Stdout, color.Output = w, w
+	func() {
+		defer func() { os.Stdout, color.Output = stdout, colorOutput }()
+		print()
+	}()
+
+	w.Close()
+	<-done
+	r.Close()
+	return buf.Bytes()
+}
+
+// displayLines counts the terminal lines output takes up, including wrapping
+func displayLines(output []byte, width int) int {
+	lines := 0
+	for _, line := range strings.Split(strings.TrimSuffix(string(output), "\n"), "\n") {
+		n := utf8.RuneCountInString(ansiPattern.ReplaceAllString(line, ""))
+		if width > 0 && n > width {
+			lines += (n + width - 1) / width
+		} else {
+			lines++
+		}
+	}
+	return lines
+}
+
+// runPager shows output in the pager command, waiting for the user to quit it
+func runPager(pager string, output []byte) error {
+	args := strings.Fields(pager)
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = bytes.NewReader(output)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	// Ctrl-C belongs to the pager while it runs
+	signal.Ignore(os.Interrupt)
+	defer signal.Reset(os.Interrupt)
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	cmd.Wait()
+	return nil
+}
@@ -0,0 +1,287 @@
+package grpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"api/internal/model"
+)
+
+// CallOptions controls a single call
+type CallOptions struct {
+	// Metadata is sent with the call, like HTTP request headers
+	Metadata model.Headers
+
+	// Timeout sets the call's deadline (0 for none)
+	Timeout time.Duration
+
+	// Input holds the request messages as a sequence of JSON objects. Methods
+	// that take a single message send an empty one if Input holds none.
+	Input io.Reader
+
+	// OnMessage is called with each response message, as JSON, as it arrives
+	OnMessage func(msg string)
+}
+
+// httpStatus maps gRPC status codes to the closest HTTP status, so calls sit
+// alongside HTTP requests in history
+var httpStatus = map[codes.Code]int{
+	codes.OK:                 http.StatusOK,
+	codes.Canceled:           499,
+	codes.Unknown:            http.StatusInternalServerError,
+	codes.InvalidArgument:    http.StatusBadRequest,
+	codes.DeadlineExceeded:   http.StatusGatewayTimeout,
+	codes.NotFound:           http.StatusNotFound,
+	codes.AlreadyExists:      http.StatusConflict,
+	codes.PermissionDenied:   http.StatusForbidden,
+	codes.ResourceExhausted:  http.StatusTooManyRequests,
+	codes.FailedPrecondition: http.StatusBadRequest,
+	codes.Aborted:            http.StatusConflict,
+	codes.OutOfRange:         http.StatusBadRequest,
+	codes.Unimplemented:      http.StatusNotImplemented,
+	codes.Internal:           http.StatusInternalServerError,
+	codes.Unavailable:        http.StatusServiceUnavailable,
+	codes.DataLoss:           http.StatusInternalServerError,
+	codes.Unauthenticated:    http.StatusUnauthorized,
+}
+
+// Call invokes a method given as "package.Service/Method". Response messages
+// are delivered to OnMessage as they arrive and collected, one JSON document
+// per line, in the returned response's body. The response's Status holds the
+// gRPC status; a non-OK status is not returned as an error.
+func (c *Client) Call(ctx context.Context, method string, opts CallOptions) (*model.Response, error) {
+	md, types, err := c.findMethod(ctx, method)
+	if err != nil {
+		return nil, err
+	}
+	unmarshal := protojson.UnmarshalOptions{Resolver: types}
+	marshal := protojson.MarshalOptions{Resolver: types}
+
+	// Methods taking one message get it checked before anything is sent
+	var single proto.Message
+	if !md.IsStreamingClient() {
+		single, err = readSingleMessage(opts.Input, md.Input(), unmarshal)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var callCtx context.Context
+	var cancel context.CancelFunc
+	if opts.Timeout > 0 {
+		callCtx, cancel = context.WithTimeout(ctx, opts.Timeout)
+	} else {
+		callCtx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+	callCtx = metadata.NewOutgoingContext(callCtx, outgoingMetadata(opts.Metadata))
+
+	start := time.Now()
+	desc := &grpc.StreamDesc{
+		StreamName:    string(md.Name()),
+		ClientStreams: md.IsStreamingClient(),
+		ServerStreams: md.IsStreamingServer(),
+	}
+	fullMethod := fmt.Sprintf("/%s/%s", md.Parent().FullName(), md.Name())
+	stream, err := c.conn.NewStream(callCtx, desc, fullMethod)
+	if err != nil {
+		return statusResponse(err, nil, nil, "", time.Since(start)), nil
+	}
+
+	// Send while receiving, so bidirectional streams can interleave
+	sendErr := make(chan error, 1)
+	go func() {
+		var err error
+		if single != nil {
+			err = stream.SendMsg(single)
+		} else {
+			err = sendMessages(stream, opts.Input, md.Input(), unmarshal)
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			// io.EOF means the server ended the call; Recv reports why
+			sendErr <- err
+			cancel()
+			return
+		}
+		sendErr <- stream.CloseSend()
+	}()
+
+	// Header blocks until the server responds; it is nil if the call failed first
+	header, _ := stream.Header()
+
+	var messages []string
+	var recvErr error
+	for {
+		msg := dynamicpb.NewMessage(md.Output())
+		if recvErr = stream.RecvMsg(msg); recvErr != nil {
+			break
+		}
+		encoded, err := marshalMessage(marshal, msg)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		messages = append(messages, encoded)
+		if opts.OnMessage != nil {
+			opts.OnMessage(encoded)
+		}
+	}
+	if errors.Is(recvErr, io.EOF) {
+		recvErr = nil
+	}
+
+	// A malformed request message is reported in place of the cancellation it caused
+	select {
+	case err := <-sendErr:
+		if err != nil && status.Code(recvErr) == codes.Canceled && ctx.Err() == nil {
+			return nil, err
+		}
+	default:
+	}
+
+	return statusResponse(recvErr, header, stream.Trailer(), strings.Join(messages, "\n"), time.Since(start)), nil
+}
+
+// readSingleMessage reads the one request message of a method that takes a
+// single message, or an empty message if there is no input
+func readSingleMessage(input io.Reader, desc protoreflect.MessageDescriptor, unmarshal protojson.UnmarshalOptions) (proto.Message, error) {
+	msg := dynamicpb.NewMessage(desc)
+	if input == nil {
+		return msg, nil
+	}
+
+	decoder := json.NewDecoder(input)
+	var raw json.RawMessage
+	if err := decoder.Decode(&raw); err != nil {
+		if errors.Is(err, io.EOF) {
+			return msg, nil
+		}
+		return nil, fmt.Errorf("invalid request message: %v", err)
+	}
+	if err := unmarshal.Unmarshal(raw, msg); err != nil {
+		return nil, fmt.Errorf("invalid request message for %s: %v", desc.FullName(), err)
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("method takes a single request message, but more than one was given")
+	}
+	return msg, nil
+}
+
+// sendMessages sends each JSON message read from input as it is decoded
+func sendMessages(stream grpc.ClientStream, input io.Reader, desc protoreflect.MessageDescriptor, unmarshal protojson.UnmarshalOptions) error {
+	if input == nil {
+		return nil
+	}
+
+	decoder := json.NewDecoder(input)
+	for {
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("invalid request message: %v", err)
+		}
+		msg := dynamicpb.NewMessage(desc)
+		if err := unmarshal.Unmarshal(raw, msg); err != nil {
+			return fmt.Errorf("invalid request message for %s: %v", desc.FullName(), err)
+		}
+		if err := stream.SendMsg(msg); err != nil {
+			return err
+		}
+	}
+}
+
+// marshalMessage encodes a message as compact JSON. protojson deliberately
+// varies its whitespace, so the output is compacted to keep it stable.
+func marshalMessage(marshal protojson.MarshalOptions, msg proto.Message) (string, error) {
+	encoded, err := marshal.Marshal(msg)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode response message: %w", err)
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, encoded); err != nil {
+		return "", err
+	}
+	return compact.String(), nil
+}
+
+// statusResponse records the outcome of a call as a response
+func statusResponse(err error, header, trailer metadata.MD, body string, duration time.Duration) *model.Response {
+	st := status.Convert(err)
+
+	headers := fromMetadata(header)
+	for k, values := range fromMetadata(trailer) {
+		for _, v := range values {
+			headers.Add(k, v)
+		}
+	}
+
+	statusText := st.Code().String()
+	if st.Message() != "" {
+		statusText += ": " + st.Message()
+	}
+
+	code, ok := httpStatus[st.Code()]
+	if !ok {
+		code = http.StatusInternalServerError
+	}
+
+	return &model.Response{
+		StatusCode:  code,
+		Status:      statusText,
+		Protocol:    "gRPC",
+		Headers:     headers,
+		Body:        body,
+		ContentType: "application/json",
+		DurationMs:  duration.Milliseconds(),
+	}
+}
+
+// outgoingMetadata converts headers to gRPC metadata. Values of binary
+// ("-bin") keys are given base64-encoded.
+func outgoingMetadata(headers model.Headers) metadata.MD {
+	md := metadata.MD{}
+	for k, values := range headers {
+		for _, v := range values {
+			if strings.HasSuffix(strings.ToLower(k), "-bin") {
+				if decoded, err := base64.StdEncoding.DecodeString(v); err == nil {
+					v = string(decoded)
+				}
+			}
+			md.Append(k, v)
+		}
+	}
+	return md
+}
+
+// fromMetadata converts gRPC metadata to headers, base64-encoding binary values
+func fromMetadata(md metadata.MD) model.Headers {
+	headers := make(model.Headers, len(md))
+	for k, values := range md {
+		for _, v := range values {
+			if strings.HasSuffix(k, "-bin") {
+				v = base64.StdEncoding.EncodeToString([]byte(v))
+			}
+			headers.Add(k, v)
+		}
+	}
+	return headers
+}
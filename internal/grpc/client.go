@@ -0,0 +1,158 @@
+// Package grpc calls gRPC methods described by server reflection or .proto
+// files, converting messages to and from JSON.
+package grpc
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"api/internal/model"
+)
+
+// Options controls how a Client connects and finds method descriptions
+type Options struct {
+	// Plaintext connects without TLS
+	Plaintext bool
+
+	// ProtoFiles describe the server's services; when empty the server's
+	// reflection service is asked instead
+	ProtoFiles []string
+
+	// ImportPaths are searched for ProtoFiles and their imports
+	ImportPaths []string
+}
+
+// Client is a connection to a gRPC server
+type Client struct {
+	conn   *grpc.ClientConn
+	source descriptorSource
+}
+
+// descriptorSource finds the descriptions of a server's services
+type descriptorSource interface {
+	// Services lists the fully-qualified names of the services offered
+	Services(ctx context.Context) ([]string, error)
+
+	// Files returns a registry holding the service and the types it uses
+	Files(ctx context.Context, service string) (*protoregistry.Files, error)
+}
+
+// Dial connects to a gRPC server at host:port. The connection is made lazily,
+// when the first call or reflection request is sent.
+func Dial(target string, opts Options) (*Client, error) {
+	host, port, err := net.SplitHostPort(target)
+	if err != nil || host == "" || port == "" {
+		return nil, fmt.Errorf("invalid target %q (expected host:port)", target)
+	}
+
+	creds := credentials.NewTLS(&tls.Config{})
+	if opts.Plaintext {
+		fmt.Fprintln(os.Stderr, "WARNING: Using plaintext gRPC connection. Data will be transmitted unencrypted.")
+		creds = insecure.NewCredentials()
+	}
+
+	conn, err := grpc.NewClient(target, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Client{conn: conn}
+	if len(opts.ProtoFiles) > 0 {
+		c.source, err = loadProtoFiles(opts.ImportPaths, opts.ProtoFiles)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+	} else {
+		c.source = &reflectionSource{conn: conn}
+	}
+	return c, nil
+}
+
+// Close closes the connection
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// ListServices returns the names of the services the server offers, sorted
+func (c *Client) ListServices(ctx context.Context) ([]string, error) {
+	services, err := c.source.Services(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(services)
+	return services, nil
+}
+
+// DescribeService lists the methods of a service
+func (c *Client) DescribeService(ctx context.Context, name string) (*model.GRPCService, error) {
+	svc, _, err := c.findService(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.GRPCService{Name: string(svc.FullName())}
+	methods := svc.Methods()
+	for i := 0; i < methods.Len(); i++ {
+		result.Methods = append(result.Methods, describeMethod(methods.Get(i)))
+	}
+	return result, nil
+}
+
+// findService looks up a service and a resolver for the message types it uses
+func (c *Client) findService(ctx context.Context, name string) (protoreflect.ServiceDescriptor, *dynamicpb.Types, error) {
+	files, err := c.source.Files(ctx, name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	desc, err := files.FindDescriptorByName(protoreflect.FullName(name))
+	if err != nil {
+		return nil, nil, fmt.Errorf("service %q not found", name)
+	}
+	svc, ok := desc.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, nil, fmt.Errorf("%q is not a service", name)
+	}
+	return svc, dynamicpb.NewTypes(files), nil
+}
+
+// findMethod looks up a method given as "package.Service/Method"
+func (c *Client) findMethod(ctx context.Context, name string) (protoreflect.MethodDescriptor, *dynamicpb.Types, error) {
+	service, method, ok := strings.Cut(name, "/")
+	if !ok || service == "" || method == "" {
+		return nil, nil, fmt.Errorf("invalid method %q (expected package.Service/Method)", name)
+	}
+
+	svc, types, err := c.findService(ctx, service)
+	if err != nil {
+		return nil, nil, err
+	}
+	md := svc.Methods().ByName(protoreflect.Name(method))
+	if md == nil {
+		return nil, nil, fmt.Errorf("service %q has no method %q", service, method)
+	}
+	return md, types, nil
+}
+
+func describeMethod(md protoreflect.MethodDescriptor) model.GRPCMethod {
+	return model.GRPCMethod{
+		Name:            string(md.Name()),
+		InputType:       string(md.Input().FullName()),
+		OutputType:      string(md.Output().FullName()),
+		ClientStreaming: md.IsStreamingClient(),
+		ServerStreaming: md.IsStreamingServer(),
+	}
+}
@@ -0,0 +1,209 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/bufbuild/protocompile"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// protoFileSource describes services with compiled .proto files
+type protoFileSource struct {
+	files *protoregistry.Files
+}
+
+// loadProtoFiles compiles .proto files, resolving imports from importPaths
+// and the well-known types bundled with protobuf
+func loadProtoFiles(importPaths, names []string) (*protoFileSource, error) {
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{ImportPaths: importPaths}),
+	}
+	compiled, err := compiler.Compile(context.Background(), names...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile proto files: %w", err)
+	}
+
+	files := new(protoregistry.Files)
+	for _, f := range compiled {
+		if err := registerFile(files, f); err != nil {
+			return nil, err
+		}
+	}
+	return &protoFileSource{files: files}, nil
+}
+
+// registerFile adds a file and, first, everything it imports
+func registerFile(files *protoregistry.Files, fd protoreflect.FileDescriptor) error {
+	if _, err := files.FindFileByPath(fd.Path()); err == nil {
+		return nil
+	}
+	imports := fd.Imports()
+	for i := 0; i < imports.Len(); i++ {
+		if err := registerFile(files, imports.Get(i).FileDescriptor); err != nil {
+			return err
+		}
+	}
+	return files.RegisterFile(fd)
+}
+
+func (s *protoFileSource) Services(ctx context.Context) ([]string, error) {
+	var services []string
+	s.files.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+		for i := 0; i < fd.Services().Len(); i++ {
+			services = append(services, string(fd.Services().Get(i).FullName()))
+		}
+		return true
+	})
+	return services, nil
+}
+
+func (s *protoFileSource) Files(ctx context.Context, service string) (*protoregistry.Files, error) {
+	return s.files, nil
+}
+
+// reflectionSource asks the server's reflection service for descriptions
+type reflectionSource struct {
+	conn *grpc.ClientConn
+
+	mu     sync.Mutex
+	stream reflectionpb.ServerReflection_ServerReflectionInfoClient
+}
+
+// errNoReflection explains what to do when a server doesn't offer reflection
+var errNoReflection = errors.New("the server does not support reflection; describe its services with --proto files")
+
+func (s *reflectionSource) Services(ctx context.Context) ([]string, error) {
+	resp, err := s.send(ctx, &reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var services []string
+	for _, svc := range resp.GetListServicesResponse().GetService() {
+		services = append(services, svc.GetName())
+	}
+	return services, nil
+}
+
+func (s *reflectionSource) Files(ctx context.Context, service string) (*protoregistry.Files, error) {
+	resp, err := s.send(ctx, &reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: service},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp.GetErrorResponse() != nil {
+		return nil, fmt.Errorf("service %q not found", service)
+	}
+
+	// The server may leave out imports; fetch any that are missing by name
+	set := make(map[string]*descriptorpb.FileDescriptorProto)
+	if err := addFileProtos(set, resp); err != nil {
+		return nil, err
+	}
+	for {
+		missing := missingImports(set)
+		if len(missing) == 0 {
+			break
+		}
+		for _, name := range missing {
+			resp, err := s.send(ctx, &reflectionpb.ServerReflectionRequest{
+				MessageRequest: &reflectionpb.ServerReflectionRequest_FileByFilename{FileByFilename: name},
+			})
+			if err != nil {
+				return nil, err
+			}
+			if resp.GetErrorResponse() != nil {
+				return nil, fmt.Errorf("server could not describe %s: %s", name, resp.GetErrorResponse().GetErrorMessage())
+			}
+			if err := addFileProtos(set, resp); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	fdset := &descriptorpb.FileDescriptorSet{}
+	for _, fdp := range set {
+		fdset.File = append(fdset.File, fdp)
+	}
+	files, err := protodesc.NewFiles(fdset)
+	if err != nil {
+		return nil, fmt.Errorf("invalid descriptors from server: %w", err)
+	}
+	return files, nil
+}
+
+// send makes one request on the reflection stream, opening it on first use
+func (s *reflectionSource) send(ctx context.Context, req *reflectionpb.ServerReflectionRequest) (*reflectionpb.ServerReflectionResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stream == nil {
+		stream, err := reflectionpb.NewServerReflectionClient(s.conn).ServerReflectionInfo(ctx)
+		if err != nil {
+			return nil, reflectionError(err)
+		}
+		s.stream = stream
+	}
+
+	if err := s.stream.Send(req); err != nil {
+		if errors.Is(err, io.EOF) {
+			// The real error is reported by Recv
+			_, err = s.stream.Recv()
+		}
+		return nil, reflectionError(err)
+	}
+	resp, err := s.stream.Recv()
+	if err != nil {
+		return nil, reflectionError(err)
+	}
+	return resp, nil
+}
+
+func reflectionError(err error) error {
+	if status.Code(err) == codes.Unimplemented {
+		return errNoReflection
+	}
+	return fmt.Errorf("reflection request failed: %w", err)
+}
+
+// addFileProtos decodes the file descriptors in a reflection response into set
+func addFileProtos(set map[string]*descriptorpb.FileDescriptorProto, resp *reflectionpb.ServerReflectionResponse) error {
+	for _, raw := range resp.GetFileDescriptorResponse().GetFileDescriptorProto() {
+		fdp := &descriptorpb.FileDescriptorProto{}
+		if err := proto.Unmarshal(raw, fdp); err != nil {
+			return fmt.Errorf("invalid descriptor from server: %w", err)
+		}
+		set[fdp.GetName()] = fdp
+	}
+	return nil
+}
+
+// missingImports lists files imported by files in set that it doesn't hold
+func missingImports(set map[string]*descriptorpb.FileDescriptorProto) []string {
+	seen := make(map[string]bool)
+	var missing []string
+	for _, fdp := range set {
+		for _, dep := range fdp.GetDependency() {
+			if _, ok := set[dep]; !ok && !seen[dep] {
+				seen[dep] = true
+				missing = append(missing, dep)
+			}
+		}
+	}
+	return missing
+}
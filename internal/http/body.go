@@ -0,0 +1,147 @@
+package http
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// quoteEscaper escapes quoted-string values in Content-Disposition headers
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// Body is a request payload that is streamed to the server rather than
+// held in memory as a string
+type Body struct {
+	Reader          io.Reader
+	ContentType     string
+	ContentEncoding string // set when the payload is compressed, e.g. "gzip"
+	ContentLength   int64  // -1 when unknown (sent with chunked transfer encoding)
+}
+
+// streamed reports whether the body is read as it is sent, from a file, pipe
+// or encoder, rather than held in memory
+func (b *Body) streamed() bool {
+	switch b.Reader.(type) {
+	case *strings.Reader, *bytes.Reader:
+		return false
+	}
+	return true
+}
+
+// NewStringBody creates a body from an in-memory string
+func NewStringBody(body string) *Body {
+	return &Body{
+		Reader:        strings.NewReader(body),
+		ContentType:   DetectContentType([]byte(body), ""),
+		ContentLength: int64(len(body)),
+	}
+}
+
+// FormField is a single form field; File is set for file uploads
+type FormField struct {
+	Name        string
+	Value       string
+	File        string // path of the file to upload
+	ContentType string // MIME type of the uploaded file
+}
+
+// NewMultipartBody builds a multipart/form-data body that streams file
+// contents as they are sent. All files are opened up front so that missing
+// files are reported before the request starts.
+func NewMultipartBody(fields []FormField) (*Body, error) {
+	files := make([]*os.File, len(fields))
+	closeFiles := func() {
+		for _, f := range files {
+			if f != nil {
+				f.Close()
+			}
+		}
+	}
+
+	for i, field := range fields {
+		if field.File == "" {
+			continue
+		}
+		f, err := os.Open(field.File)
+		if err != nil {
+			closeFiles()
+			return nil, err
+		}
+		files[i] = f
+	}
+
+	pr, pw := io.Pipe()
+	writer := multipart.NewWriter(pw)
+
+	go func() {
+		defer closeFiles()
+		pw.CloseWithError(writeMultipart(writer, fields, files))
+	}()
+
+	return &Body{
+		Reader:        pr,
+		ContentType:   writer.FormDataContentType(),
+		ContentLength: -1,
+	}, nil
+}
+
+// writeMultipart writes every field as a part and closes the multipart writer
+func writeMultipart(writer *multipart.Writer, fields []FormField, files []*os.File) error {
+	for i, field := range fields {
+		if files[i] == nil {
+			if err := writer.WriteField(field.Name, field.Value); err != nil {
+				return err
+			}
+			continue
+		}
+
+		contentType := field.ContentType
+		if contentType == "" {
+			contentType = mime.TypeByExtension(filepath.Ext(field.File))
+		}
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+			quoteEscaper.Replace(field.Name), quoteEscaper.Replace(filepath.Base(field.File))))
+		h.Set("Content-Type", contentType)
+
+		part, err := writer.CreatePart(h)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(part, files[i]); err != nil {
+			return err
+		}
+	}
+	return writer.Close()
+}
+
+// NewURLEncodedBody builds an application/x-www-form-urlencoded body.
+// File fields are not supported in this mode.
+func NewURLEncodedBody(fields []FormField) (*Body, error) {
+	// Encode by hand rather than with url.Values to keep the fields in order
+	pairs := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if field.File != "" {
+			return nil, fmt.Errorf("file field '%s' requires multipart form data (omit --form)", field.Name)
+		}
+		pairs = append(pairs, url.QueryEscape(field.Name)+"="+url.QueryEscape(field.Value))
+	}
+
+	encoded := strings.Join(pairs, "&")
+	return &Body{
+		Reader:        strings.NewReader(encoded),
+		ContentType:   "application/x-www-form-urlencoded",
+		ContentLength: int64(len(encoded)),
+	}, nil
+}
@@ -22,20 +22,104 @@ const (
 
 // Client wraps the standard http.Client with additional functionality
 type Client struct {
-	client *http.Client
+	client         *http.Client
+	redirects      RedirectPolicy
+	acceptEncoding string
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithCookieJar stores cookies set by responses in jar and sends them with
+// later requests, including redirects
+func WithCookieJar(jar http.CookieJar) Option {
+	return func(c *Client) {
+		c.client.Jar = jar
+	}
 }
 
 // NewClient creates a new HTTP client
-func NewClient() *Client {
-	return &Client{
+func NewClient(opts ...Option) *Client {
+	c := &Client{
 		client: &http.Client{
-			Timeout: DefaultTimeout,
+			Timeout:   DefaultTimeout,
+			Transport: newTransport(ProtocolAuto),
 		},
+		redirects: DefaultRedirectPolicy(),
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 // Do executes an HTTP request and returns the response
-func (c *Client) Do(method, reqURL string, headers map[string]string, body string) (*model.Response, error) {
+func (c *Client) Do(method, reqURL string, headers model.Headers, body string) (*model.Response, error) {
+	var reqBody *Body
+	if body != "" {
+		reqBody = NewStringBody(body)
+	}
+	return c.DoBody(method, reqURL, headers, reqBody)
+}
+
+// DoBody executes an HTTP request with a streamed body and returns the response.
+// A nil body sends no payload.
+func (c *Client) DoBody(method, reqURL string, headers model.Headers, body *Body) (*model.Response, error) {
+	req, err := newRequest(method, reqURL, headers, body)
+	if err != nil {
+		return nil, err
+	}
+	c.setAcceptEncoding(req)
+	if body != nil {
+		if closer, ok := body.Reader.(io.Closer); ok {
+			defer closer.Close()
+		}
+	}
+
+	client, trace := c.tracedClient(headers)
+
+	// The overall client timeout would also limit uploading a streamed body,
+	// so those are bounded only while connecting and waiting for the response
+	var timeout *responseTimeout
+	if body != nil && body.streamed() {
+		req, timeout = withResponseTimeout(req)
+		defer timeout.release()
+		client.Timeout = 0
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		if timeout != nil && timeout.timedOut() {
+			err = fmt.Errorf("timed out after %s waiting for a response", DefaultTimeout)
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	duration := time.Since(start)
+
+	decoded, err := decodeBody(resp)
+	if err != nil {
+		return nil, err
+	}
+	defer decoded.Close()
+	respBody, err := readLimitedBody(decoded)
+	if err != nil {
+		if timeout != nil && timeout.timedOut() {
+			err = fmt.Errorf("timed out after %s reading the response", DefaultTimeout)
+		}
+		return nil, err
+	}
+
+	result := buildResponse(resp, respBody, duration)
+	result.WireSize = decoded.WireSize()
+	result.Redirects = trace.hops
+	return result, nil
+}
+
+// newRequest validates the URL and builds an http.Request with headers and body
+func newRequest(method, reqURL string, headers model.Headers, body *Body) (*http.Request, error) {
 	// Validate URL and check for SSRF risks
 	if err := validateURL(reqURL); err != nil {
 		return nil, err
@@ -47,36 +131,41 @@ func (c *Client) Do(method, reqURL string, headers map[string]string, body strin
 	}
 
 	var bodyReader io.Reader
-	if body != "" {
-		bodyReader = strings.NewReader(body)
+	if body != nil {
+		bodyReader = body.Reader
 	}
 
 	req, err := http.NewRequest(method, reqURL, bodyReader)
 	if err != nil {
 		return nil, err
 	}
+	if body != nil {
+		// A negative length makes the transport use chunked transfer encoding
+		req.ContentLength = body.ContentLength
+	}
 
-	// Set headers
-	for key, value := range headers {
-		req.Header.Set(key, value)
+	// Set headers, sending repeated headers once per value
+	for key, values := range headers {
+		req.Header.Del(key)
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
 	}
 
 	// Default Content-Type for requests with body
-	if body != "" && req.Header.Get("Content-Type") == "" {
-		req.Header.Set("Content-Type", "application/json")
+	if body != nil && body.ContentType != "" && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", body.ContentType)
 	}
-
-	start := time.Now()
-	resp, err := c.client.Do(req)
-	if err != nil {
-		return nil, err
+	if body != nil && body.ContentEncoding != "" {
+		req.Header.Set("Content-Encoding", body.ContentEncoding)
 	}
-	defer resp.Body.Close()
 
-	duration := time.Since(start)
+	return req, nil
+}
 
-	// Read response body with size limit to prevent memory exhaustion
-	limitedReader := io.LimitReader(resp.Body, MaxResponseSize+1)
+// readLimitedBody reads a response body with a size limit to prevent memory exhaustion
+func readLimitedBody(r io.Reader) ([]byte, error) {
+	limitedReader := io.LimitReader(r, MaxResponseSize+1)
 	respBody, err := io.ReadAll(limitedReader)
 	if err != nil {
 		return nil, err
@@ -88,45 +177,50 @@ func (c *Client) Do(method, reqURL string, headers map[string]string, body strin
 		fmt.Fprintln(os.Stderr, "WARNING: Response body truncated (exceeded 50MB limit)")
 	}
 
-	// Convert response headers
-	respHeaders := make(map[string]string)
+	return respBody, nil
+}
+
+// buildResponse converts an http.Response and its body into a model.Response
+func buildResponse(resp *http.Response, body []byte, duration time.Duration) *model.Response {
+	// Convert response headers, keeping every value of repeated headers
+	respHeaders := make(model.Headers, len(resp.Header))
 	for key, values := range resp.Header {
-		if len(values) > 0 {
-			respHeaders[key] = values[0]
-		}
+		respHeaders[key] = append([]string(nil), values...)
 	}
 
 	return &model.Response{
-		StatusCode: resp.StatusCode,
-		Status:     resp.Status,
-		Headers:    respHeaders,
-		Body:       string(respBody),
-		DurationMs: duration.Milliseconds(),
-	}, nil
+		StatusCode:  resp.StatusCode,
+		Status:      resp.Status,
+		Protocol:    resp.Proto,
+		Headers:     respHeaders,
+		Body:        string(body),
+		ContentType: resp.Header.Get("Content-Type"),
+		DurationMs:  duration.Milliseconds(),
+	}
 }
 
 // Get performs a GET request
-func (c *Client) Get(url string, headers map[string]string) (*model.Response, error) {
+func (c *Client) Get(url string, headers model.Headers) (*model.Response, error) {
 	return c.Do("GET", url, headers, "")
 }
 
 // Post performs a POST request
-func (c *Client) Post(url string, headers map[string]string, body string) (*model.Response, error) {
+func (c *Client) Post(url string, headers model.Headers, body string) (*model.Response, error) {
 	return c.Do("POST", url, headers, body)
 }
 
 // Put performs a PUT request
-func (c *Client) Put(url string, headers map[string]string, body string) (*model.Response, error) {
+func (c *Client) Put(url string, headers model.Headers, body string) (*model.Response, error) {
 	return c.Do("PUT", url, headers, body)
 }
 
 // Patch performs a PATCH request
-func (c *Client) Patch(url string, headers map[string]string, body string) (*model.Response, error) {
+func (c *Client) Patch(url string, headers model.Headers, body string) (*model.Response, error) {
 	return c.Do("PATCH", url, headers, body)
 }
 
 // Delete performs a DELETE request
-func (c *Client) Delete(url string, headers map[string]string) (*model.Response, error) {
+func (c *Client) Delete(url string, headers model.Headers) (*model.Response, error) {
 	return c.Do("DELETE", url, headers, "")
 }
 
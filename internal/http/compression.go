@@ -0,0 +1,251 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// DefaultAcceptEncoding lists every content coding the client can decode
+const DefaultAcceptEncoding = "gzip, deflate, br, zstd"
+
+// WithAcceptEncoding sends encodings as the Accept-Encoding header of every
+// request that doesn't set its own. Without it only gzip is requested, and
+// not for HEAD requests, as net/http does. Range requests never ask for
+// compression: a slice of an encoded body can't be decoded on its own.
+func WithAcceptEncoding(encodings string) Option {
+	return func(c *Client) {
+		c.acceptEncoding = encodings
+	}
+}
+
+// setAcceptEncoding asks for compressed responses unless the request already does
+func (c *Client) setAcceptEncoding(req *http.Request) {
+	if req.Header.Get("Accept-Encoding") != "" || req.Header.Get("Range") != "" {
+		return
+	}
+	if c.acceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", c.acceptEncoding)
+	} else if req.Method != http.MethodHead {
+		req.Header.Set("Accept-Encoding", "gzip")
+	}
+}
+
+// ParseEncoding normalizes a content coding name, accepting the aliases
+// servers commonly use
+func ParseEncoding(name string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "gzip", "x-gzip":
+		return "gzip", nil
+	case "deflate":
+		return "deflate", nil
+	case "br", "brotli":
+		return "br", nil
+	case "zstd":
+		return "zstd", nil
+	}
+	return "", fmt.Errorf("unsupported encoding %q (expected gzip, deflate, br or zstd)", name)
+}
+
+// CompressBody returns body compressed with encoding, labelled with a matching
+// Content-Encoding. In-memory bodies are compressed up front so their length
+// is known; streamed bodies are compressed as they are sent.
+func CompressBody(body *Body, encoding string) (*Body, error) {
+	encoding, err := ParseEncoding(encoding)
+	if err != nil {
+		return nil, err
+	}
+
+	compressed := &Body{ContentType: body.ContentType, ContentEncoding: encoding}
+	switch body.Reader.(type) {
+	case *strings.Reader, *bytes.Reader:
+		var buf bytes.Buffer
+		if err := compressTo(&buf, body.Reader, encoding); err != nil {
+			return nil, err
+		}
+		compressed.Reader = bytes.NewReader(buf.Bytes())
+		compressed.ContentLength = int64(buf.Len())
+		return compressed, nil
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		err := compressTo(pw, body.Reader, encoding)
+		if closer, ok := body.Reader.(io.Closer); ok {
+			closer.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+	compressed.Reader = pr
+	compressed.ContentLength = -1
+	return compressed, nil
+}
+
+// compressTo writes src to dst compressed with encoding
+func compressTo(dst io.Writer, src io.Reader, encoding string) error {
+	var w io.WriteCloser
+	switch encoding {
+	case "gzip":
+		w = gzip.NewWriter(dst)
+	case "deflate":
+		w = zlib.NewWriter(dst)
+	case "br":
+		w = brotli.NewWriter(dst)
+	case "zstd":
+		zw, err := zstd.NewWriter(dst)
+		if err != nil {
+			return err
+		}
+		w = zw
+	default:
+		return fmt.Errorf("unsupported encoding %q", encoding)
+	}
+	if _, err := io.Copy(w, src); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// decodedBody reads a response body with its Content-Encoding undone,
+// counting the bytes received on the wire
+type decodedBody struct {
+	io.Reader
+	wire    *countingReader
+	closers []io.Closer
+	encoded bool
+}
+
+// WireSize returns the number of encoded bytes read so far, or 0 if the
+// body was not encoded
+func (b *decodedBody) WireSize() int64 {
+	if !b.encoded {
+		return 0
+	}
+	return b.wire.n
+}
+
+// Close releases the decoders; the response body is closed by its owner
+func (b *decodedBody) Close() error {
+	for _, c := range b.closers {
+		c.Close()
+	}
+	return nil
+}
+
+// countingReader counts the bytes read through it, remembering the last error
+type countingReader struct {
+	r   io.Reader
+	n   int64
+	err error
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	if err != nil && err != io.EOF {
+		c.err = err
+	}
+	return n, err
+}
+
+// decodeBody undoes the codings listed in resp's Content-Encoding, last
+// applied first. Unknown codings are reported and the body is left as sent.
+func decodeBody(resp *http.Response) (*decodedBody, error) {
+	wire := &countingReader{r: resp.Body}
+	body := &decodedBody{Reader: wire, wire: wire}
+
+	var codings []string
+	for _, value := range resp.Header.Values("Content-Encoding") {
+		for _, coding := range strings.Split(value, ",") {
+			if coding = strings.ToLower(strings.TrimSpace(coding)); coding != "" && coding != "identity" {
+				codings = append(codings, coding)
+			}
+		}
+	}
+	if len(codings) == 0 {
+		return body, nil
+	}
+	for _, coding := range codings {
+		if _, err := ParseEncoding(coding); err != nil {
+			fmt.Fprintf(os.Stderr, "WARNING: Response has unsupported Content-Encoding %q; showing the body as received\n", coding)
+			return body, nil
+		}
+	}
+
+	// Empty bodies (HEAD, 204, 304) carry the header but nothing to decode
+	buffered := bufio.NewReader(wire)
+	if _, err := buffered.Peek(1); err == io.EOF {
+		return body, nil
+	}
+
+	body.encoded = true
+	var r io.Reader = buffered
+	for i := len(codings) - 1; i >= 0; i-- {
+		coding, _ := ParseEncoding(codings[i])
+		decoder, err := newDecoder(r, coding)
+		if err != nil {
+			body.Close()
+			return nil, fmt.Errorf("invalid %s response body: %w", coding, err)
+		}
+		body.closers = append(body.closers, decoder)
+		r = &decodeErrorReader{r: decoder, wire: wire, coding: coding}
+	}
+	body.Reader = r
+	return body, nil
+}
+
+// newDecoder returns a reader decoding r from encoding
+func newDecoder(r io.Reader, encoding string) (io.ReadCloser, error) {
+	switch encoding {
+	case "gzip":
+		return gzip.NewReader(r)
+	case "deflate":
+		// deflate should be zlib-wrapped, but some servers send raw DEFLATE
+		buffered := bufio.NewReader(r)
+		if header, err := buffered.Peek(2); err == nil && isZlibHeader(header) {
+			return zlib.NewReader(buffered)
+		}
+		return flate.NewReader(buffered), nil
+	case "br":
+		return io.NopCloser(brotli.NewReader(r)), nil
+	case "zstd":
+		decoder, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return decoder.IOReadCloser(), nil
+	}
+	return nil, fmt.Errorf("unsupported encoding %q", encoding)
+}
+
+// isZlibHeader reports whether header starts a zlib stream (RFC 1950)
+func isZlibHeader(header []byte) bool {
+	return header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0
+}
+
+// decodeErrorReader names the coding in errors from a decoder, leaving
+// errors reading from the connection itself as they are
+type decodeErrorReader struct {
+	r      io.Reader
+	wire   *countingReader
+	coding string
+}
+
+func (d *decodeErrorReader) Read(p []byte) (int, error) {
+	n, err := d.r.Read(p)
+	if err != nil && err != io.EOF && d.wire.err == nil {
+		err = fmt.Errorf("invalid %s response body: %w", d.coding, err)
+	}
+	return n, err
+}
@@ -0,0 +1,165 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// urlEncodedPattern matches bodies that look like key=value&key=value
+var urlEncodedPattern = regexp.MustCompile(`^[^=&\s]+=[^&\s]*(&[^=&\s]+=[^&\s]*)*$`)
+
+// contentTypeShortcuts maps short names accepted by --content-type to MIME types
+var contentTypeShortcuts = map[string]string{
+	"json":      "application/json",
+	"xml":       "application/xml",
+	"form":      "application/x-www-form-urlencoded",
+	"multipart": "multipart/form-data",
+	"text":      "text/plain",
+	"html":      "text/html",
+}
+
+// ExpandContentType expands a --content-type shortcut (e.g. "json") to its MIME type
+func ExpandContentType(contentType string) string {
+	if expanded, ok := contentTypeShortcuts[strings.ToLower(contentType)]; ok {
+		return expanded
+	}
+	return contentType
+}
+
+// DetectContentType guesses the Content-Type of a request body. If filename is
+// set, its extension is tried first; then the body is checked for JSON, XML and
+// URL-encoded forms before falling back to MIME sniffing.
+func DetectContentType(body []byte, filename string) string {
+	if filename != "" {
+		if byExt := mime.TypeByExtension(filepath.Ext(filename)); byExt != "" {
+			return byExt
+		}
+	}
+
+	trimmed := bytes.TrimSpace(body)
+	switch {
+	case json.Valid(trimmed):
+		return "application/json"
+	case looksLikeHTML(trimmed):
+		return "text/html; charset=utf-8"
+	case looksLikeXML(trimmed):
+		return "application/xml"
+	case urlEncodedPattern.Match(trimmed):
+		return "application/x-www-form-urlencoded"
+	}
+
+	return http.DetectContentType(body)
+}
+
+// IsJSONContentType reports whether a Content-Type denotes a JSON payload
+func IsJSONContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = strings.ToLower(strings.TrimSpace(contentType))
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// LooksLikeJSON reports whether a body appears to be meant as JSON
+func LooksLikeJSON(body []byte) bool {
+	trimmed := bytes.TrimSpace(body)
+	return len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[')
+}
+
+// ValidateJSON checks that body is valid JSON. Syntax errors report the line
+// and column of the problem along with the offending line.
+func ValidateJSON(body []byte) error {
+	var v interface{}
+	err := json.Unmarshal(body, &v)
+	if err == nil {
+		return nil
+	}
+
+	var syntaxErr *json.SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	line, col, text := locateOffset(body, syntaxErr.Offset)
+	return fmt.Errorf("invalid JSON at line %d, column %d: %v\n  %s\n  %s^",
+		line, col, syntaxErr, text, strings.Repeat(" ", col-1))
+}
+
+// locateOffset converts a byte offset reported by encoding/json into a 1-based
+// line and column, and returns the text of that line
+func locateOffset(body []byte, offset int64) (line, col int, text string) {
+	// The offset counts the bytes read, so the offending byte is the one before it
+	pos := int(offset) - 1
+	if pos < 0 {
+		pos = 0
+	}
+	if pos > len(body) {
+		pos = len(body)
+	}
+
+	lineStart := bytes.LastIndexByte(body[:pos], '\n') + 1
+	lineEnd := bytes.IndexByte(body[lineStart:], '\n')
+	if lineEnd == -1 {
+		lineEnd = len(body)
+	} else {
+		lineEnd += lineStart
+	}
+
+	line = bytes.Count(body[:lineStart], []byte("\n")) + 1
+	col = pos - lineStart + 1
+	text = strings.TrimRight(string(body[lineStart:lineEnd]), "\r")
+
+	// Keep long (e.g. minified) lines readable by showing a window around the error
+	const window = 60
+	if len(text) > 2*window {
+		start := col - window
+		if start < 0 {
+			start = 0
+		}
+		end := start + 2*window
+		if end > len(text) {
+			end = len(text)
+		}
+		text = text[start:end]
+		col -= start
+	}
+
+	return line, col, text
+}
+
+// looksLikeHTML reports whether a body is an HTML document
+func looksLikeHTML(body []byte) bool {
+	lower := strings.ToLower(string(body[:min(len(body), 64)]))
+	return strings.HasPrefix(lower, "<!doctype html") || strings.HasPrefix(lower, "<html")
+}
+
+// looksLikeXML reports whether a body is a well-formed XML document
+func looksLikeXML(body []byte) bool {
+	if len(body) == 0 || body[0] != '<' {
+		return false
+	}
+
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	sawElement := false
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			return sawElement
+		}
+		if err != nil {
+			return false
+		}
+		if _, ok := tok.(xml.StartElement); ok {
+			sawElement = true
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package http
+
+import (
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"api/internal/model"
+)
+
+// SessionJar is a cookie jar that remembers every cookie it accepts, so that
+// its contents can be saved to a session and restored by a later run.
+// Matching cookies to requests is left to net/http/cookiejar.
+type SessionJar struct {
+	mu      sync.Mutex
+	jar     *cookiejar.Jar
+	cookies map[string]model.Cookie // keyed by domain, path and name
+}
+
+// NewSessionJar creates a jar holding the given cookies. Expired cookies are dropped.
+func NewSessionJar(cookies []model.Cookie) (*SessionJar, error) {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	j := &SessionJar{jar: jar, cookies: make(map[string]model.Cookie)}
+	now := time.Now()
+	for _, c := range cookies {
+		if !c.Expires.IsZero() && !c.Expires.After(now) {
+			continue
+		}
+		j.restore(c)
+	}
+	return j, nil
+}
+
+// restore loads a saved cookie into the underlying jar as if the cookie's own
+// domain had just set it
+func (j *SessionJar) restore(c model.Cookie) {
+	scheme := "http"
+	if c.Secure {
+		scheme = "https"
+	}
+	u := &url.URL{Scheme: scheme, Host: c.Domain, Path: c.Path}
+
+	hc := &http.Cookie{
+		Name:     c.Name,
+		Value:    c.Value,
+		Path:     c.Path,
+		Expires:  c.Expires,
+		Secure:   c.Secure,
+		HttpOnly: c.HttpOnly,
+	}
+	if !c.HostOnly {
+		hc.Domain = c.Domain
+	}
+
+	j.jar.SetCookies(u, []*http.Cookie{hc})
+	j.cookies[cookieKey(c)] = c
+}
+
+// Cookies returns the cookies to send in a request for u
+func (j *SessionJar) Cookies(u *url.URL) []*http.Cookie {
+	return j.jar.Cookies(u)
+}
+
+// SetCookies handles the receipt of the cookies in a reply for u
+func (j *SessionJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.jar.SetCookies(u, cookies)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	host := strings.ToLower(u.Hostname())
+	now := time.Now()
+	for _, hc := range cookies {
+		c := model.Cookie{
+			Name:     hc.Name,
+			Value:    hc.Value,
+			Path:     hc.Path,
+			Secure:   hc.Secure,
+			HttpOnly: hc.HttpOnly,
+		}
+
+		// Mirror the jar's domain rules closely enough to know which cookies it kept
+		if hc.Domain == "" {
+			c.Domain = host
+			c.HostOnly = true
+		} else {
+			c.Domain = strings.TrimPrefix(strings.ToLower(hc.Domain), ".")
+			if host != c.Domain && !strings.HasSuffix(host, "."+c.Domain) {
+				continue
+			}
+		}
+		if c.Path == "" || !strings.HasPrefix(c.Path, "/") {
+			c.Path = defaultCookiePath(u.Path)
+		}
+
+		// A past expiry or a non-positive Max-Age deletes the cookie
+		switch {
+		case hc.MaxAge < 0:
+			delete(j.cookies, cookieKey(c))
+			continue
+		case hc.MaxAge > 0:
+			c.Expires = now.Add(time.Duration(hc.MaxAge) * time.Second)
+		case !hc.Expires.IsZero():
+			if !hc.Expires.After(now) {
+				delete(j.cookies, cookieKey(c))
+				continue
+			}
+			c.Expires = hc.Expires
+		}
+
+		j.cookies[cookieKey(c)] = c
+	}
+}
+
+// Saved returns the unexpired cookies in the jar, sorted by domain, path and name
+func (j *SessionJar) Saved() []model.Cookie {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	now := time.Now()
+	cookies := make([]model.Cookie, 0, len(j.cookies))
+	for _, c := range j.cookies {
+		if !c.Expires.IsZero() && !c.Expires.After(now) {
+			continue
+		}
+		cookies = append(cookies, c)
+	}
+
+	sort.Slice(cookies, func(a, b int) bool {
+		return cookieKey(cookies[a]) < cookieKey(cookies[b])
+	})
+	return cookies
+}
+
+func cookieKey(c model.Cookie) string {
+	return c.Domain + ";" + c.Path + ";" + c.Name
+}
+
+// defaultCookiePath returns the path a cookie without a Path attribute applies
+// to: the directory of the request path (RFC 6265 section 5.1.4)
+func defaultCookiePath(requestPath string) string {
+	i := strings.LastIndex(requestPath, "/")
+	if i <= 0 {
+		return "/"
+	}
+	return requestPath[:i]
+}
@@ -0,0 +1,271 @@
+package http
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"api/internal/model"
+)
+
+// DownloadOptions controls how a response body is saved to disk
+type DownloadOptions struct {
+	// Path is the destination file. If empty, the name is taken from the
+	// Content-Disposition header or the last segment of the URL path.
+	Path string
+
+	// Resume continues a partial download with a Range request
+	Resume bool
+
+	// Progress, if set, wraps the file writer to report progress. start is the
+	// number of bytes already on disk and total the expected final size (-1 if unknown).
+	Progress func(w io.Writer, start, total int64) io.Writer
+}
+
+// Download executes a request and streams the response body straight to a file.
+// Error responses (4xx/5xx) are not written to disk; their body is returned as usual.
+func (c *Client) Download(method, reqURL string, headers model.Headers, body *Body, opts DownloadOptions) (*model.Response, error) {
+	req, err := newRequest(method, reqURL, headers, body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		if closer, ok := body.Reader.(io.Closer); ok {
+			defer closer.Close()
+		}
+	}
+
+	// Resuming needs the destination up front to know where to continue from
+	destPath := opts.Path
+	if destPath == "" && opts.Resume {
+		destPath = filenameFromURL(req.URL)
+	}
+
+	var offset int64
+	if opts.Resume {
+		if info, err := os.Stat(destPath); err == nil && info.Size() > 0 {
+			offset = info.Size()
+			req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		}
+	}
+	c.setAcceptEncoding(req)
+
+	// The overall client timeout would cut off large uploads and downloads, so
+	// only the wait for response headers is bounded
+	req, timeout := withResponseTimeout(req)
+	defer timeout.release()
+
+	streamClient, trace := c.tracedClient(headers)
+	streamClient.Timeout = 0
+
+	start := time.Now()
+	resp, err := streamClient.Do(req)
+	timeout.stop()
+	if err != nil {
+		if timeout.timedOut() {
+			err = fmt.Errorf("timed out after %s waiting for a response", DefaultTimeout)
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	decoded, err := decodeBody(resp)
+	if err != nil {
+		return nil, err
+	}
+	defer decoded.Close()
+
+	// Nothing left to fetch: the partial file was already complete
+	if offset > 0 && resp.StatusCode == http.StatusRequestedRangeNotSatisfiable {
+		download, err := existingDownload(destPath)
+		if err != nil {
+			return nil, err
+		}
+		result := buildResponse(resp, nil, time.Since(start))
+		result.Download = download
+		result.Redirects = trace.hops
+		return result, nil
+	}
+
+	// Error responses are reported like normal responses instead of saved
+	if resp.StatusCode >= 400 {
+		respBody, err := readLimitedBody(decoded)
+		if err != nil {
+			return nil, err
+		}
+		result := buildResponse(resp, respBody, time.Since(start))
+		result.WireSize = decoded.WireSize()
+		result.Redirects = trace.hops
+		return result, nil
+	}
+
+	if destPath == "" {
+		destPath = filenameFromResponse(resp)
+		if _, err := os.Stat(destPath); err == nil {
+			return nil, fmt.Errorf("refusing to overwrite existing file %s (use -o to choose a name)", destPath)
+		}
+	}
+
+	// Append only if the server honored the range from where we left off
+	appending := offset > 0 && resp.StatusCode == http.StatusPartialContent
+	if appending {
+		if rangeStart, ok := contentRangeStart(resp.Header.Get("Content-Range")); !ok || rangeStart != offset {
+			return nil, fmt.Errorf("server returned an unexpected range: %s", resp.Header.Get("Content-Range"))
+		}
+	} else {
+		offset = 0
+	}
+
+	download, err := writeDownload(resp, decoded, destPath, offset, appending, opts.Progress)
+	if err != nil {
+		return nil, err
+	}
+
+	result := buildResponse(resp, nil, time.Since(start))
+	result.WireSize = decoded.WireSize()
+	result.Download = download
+	result.Redirects = trace.hops
+	return result, nil
+}
+
+// writeDownload streams the decoded response body into destPath, hashing the complete file
+func writeDownload(resp *http.Response, body *decodedBody, destPath string, offset int64, appending bool, progress func(io.Writer, int64, int64) io.Writer) (*model.Download, error) {
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if appending {
+		flags = os.O_CREATE | os.O_RDWR
+	}
+
+	f, err := os.OpenFile(destPath, flags, 0644)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	// When resuming, the checksum covers the part already on disk as well
+	hasher := sha256.New()
+	if appending {
+		if _, err := io.Copy(hasher, io.LimitReader(f, offset)); err != nil {
+			return nil, err
+		}
+		if _, err := f.Seek(offset, io.SeekStart); err != nil {
+			return nil, err
+		}
+	}
+
+	// Content-Length counts encoded bytes, so the decoded size is unknown
+	total := int64(-1)
+	if resp.ContentLength >= 0 && !body.encoded {
+		total = offset + resp.ContentLength
+	}
+
+	var w io.Writer = f
+	if progress != nil {
+		w = progress(f, offset, total)
+		if closer, ok := w.(io.Closer); ok {
+			defer closer.Close()
+		}
+	}
+
+	written, err := io.Copy(io.MultiWriter(w, hasher), body)
+	if err != nil {
+		return nil, fmt.Errorf("download interrupted after %d bytes (resume with --continue): %w", offset+written, err)
+	}
+
+	absPath, err := filepath.Abs(destPath)
+	if err != nil {
+		absPath = destPath
+	}
+
+	return &model.Download{
+		Path:    absPath,
+		Size:    offset + written,
+		SHA256:  hex.EncodeToString(hasher.Sum(nil)),
+		Resumed: appending,
+	}, nil
+}
+
+// existingDownload describes a file that is already fully downloaded
+func existingDownload(destPath string) (*model.Download, error) {
+	f, err := os.Open(destPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	hasher := sha256.New()
+	size, err := io.Copy(hasher, f)
+	if err != nil {
+		return nil, err
+	}
+
+	absPath, err := filepath.Abs(destPath)
+	if err != nil {
+		absPath = destPath
+	}
+
+	return &model.Download{
+		Path:    absPath,
+		Size:    size,
+		SHA256:  hex.EncodeToString(hasher.Sum(nil)),
+		Resumed: true,
+	}, nil
+}
+
+// filenameFromResponse picks a safe local filename from Content-Disposition,
+// falling back to the URL path
+func filenameFromResponse(resp *http.Response) string {
+	if cd := resp.Header.Get("Content-Disposition"); cd != "" {
+		if _, params, err := mime.ParseMediaType(cd); err == nil {
+			if name := safeFilename(params["filename"]); name != "" {
+				return name
+			}
+		}
+	}
+	return filenameFromURL(resp.Request.URL)
+}
+
+// filenameFromURL uses the last segment of the URL path as a filename
+func filenameFromURL(u *url.URL) string {
+	if name := safeFilename(path.Base(u.Path)); name != "" {
+		return name
+	}
+	return "download"
+}
+
+// safeFilename strips any directory components so a server-supplied name
+// can only ever create a file in the current directory
+func safeFilename(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	if name == "." || name == ".." || name == "/" || strings.HasPrefix(name, ".") {
+		return ""
+	}
+	return name
+}
+
+// contentRangeStart parses the first byte position from a Content-Range header
+// like "bytes 100-199/200"
+func contentRangeStart(header string) (int64, bool) {
+	spec, ok := strings.CutPrefix(header, "bytes ")
+	if !ok {
+		return 0, false
+	}
+	startStr, _, ok := strings.Cut(spec, "-")
+	if !ok {
+		return 0, false
+	}
+	start, err := strconv.ParseInt(startStr, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return start, true
+}
@@ -0,0 +1,179 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"api/internal/model"
+)
+
+// IntrospectionQuery asks a GraphQL endpoint for its types and their fields
+const IntrospectionQuery = `query IntrospectionQuery {
+  __schema {
+    queryType { name }
+    mutationType { name }
+    subscriptionType { name }
+    types {
+      kind
+      name
+      description
+      fields(includeDeprecated: true) {
+        name
+        description
+        args { name type { ...TypeRef } defaultValue }
+        type { ...TypeRef }
+        isDeprecated
+      }
+      inputFields { name description type { ...TypeRef } defaultValue }
+      enumValues(includeDeprecated: true) { name }
+      possibleTypes { name }
+    }
+  }
+}
+
+fragment TypeRef on __Type {
+  kind
+  name
+  ofType { kind name ofType { kind name ofType { kind name ofType { kind name ofType { kind name } } } } }
+}`
+
+// ParseGraphQLResult parses a GraphQL response body. ok is false if the body
+// is not a GraphQL result (e.g. an HTML error page from a proxy).
+func ParseGraphQLResult(body string) (result *model.GraphQLResult, ok bool) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(body), &raw); err != nil {
+		return nil, false
+	}
+	if _, hasData := raw["data"]; !hasData {
+		if _, hasErrors := raw["errors"]; !hasErrors {
+			return nil, false
+		}
+	}
+
+	result = &model.GraphQLResult{}
+	if err := json.Unmarshal([]byte(body), result); err != nil {
+		return nil, false
+	}
+	if string(result.Data) == "null" {
+		result.Data = nil
+	}
+	return result, true
+}
+
+// introspectionResult mirrors the response to IntrospectionQuery
+type introspectionResult struct {
+	Schema struct {
+		QueryType        *namedType `json:"queryType"`
+		MutationType     *namedType `json:"mutationType"`
+		SubscriptionType *namedType `json:"subscriptionType"`
+		Types            []struct {
+			Kind          string              `json:"kind"`
+			Name          string              `json:"name"`
+			Description   string              `json:"description"`
+			Fields        []introspectedField `json:"fields"`
+			InputFields   []introspectedField `json:"inputFields"`
+			EnumValues    []namedType         `json:"enumValues"`
+			PossibleTypes []namedType         `json:"possibleTypes"`
+		} `json:"types"`
+	} `json:"__schema"`
+}
+
+type namedType struct {
+	Name string `json:"name"`
+}
+
+type introspectedField struct {
+	Name         string              `json:"name"`
+	Description  string              `json:"description"`
+	Args         []introspectedField `json:"args"`
+	Type         typeRef             `json:"type"`
+	DefaultValue *string             `json:"defaultValue"`
+	IsDeprecated bool                `json:"isDeprecated"`
+}
+
+// typeRef is a possibly wrapped (list or non-null) type reference
+type typeRef struct {
+	Kind   string   `json:"kind"`
+	Name   string   `json:"name"`
+	OfType *typeRef `json:"ofType"`
+}
+
+// String formats a type reference in GraphQL notation, e.g. "[User!]!"
+func (t typeRef) String() string {
+	switch {
+	case t.OfType == nil:
+		return t.Name
+	case t.Kind == "NON_NULL":
+		return t.OfType.String() + "!"
+	case t.Kind == "LIST":
+		return "[" + t.OfType.String() + "]"
+	}
+	return t.Name
+}
+
+// ParseSchema extracts the schema from the data of an introspection response
+func ParseSchema(data json.RawMessage) (*model.GraphQLSchema, error) {
+	var result introspectionResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("invalid introspection response: %w", err)
+	}
+	if result.Schema.Types == nil {
+		return nil, fmt.Errorf("invalid introspection response: no types")
+	}
+
+	schema := &model.GraphQLSchema{}
+	if t := result.Schema.QueryType; t != nil {
+		schema.QueryType = t.Name
+	}
+	if t := result.Schema.MutationType; t != nil {
+		schema.MutationType = t.Name
+	}
+	if t := result.Schema.SubscriptionType; t != nil {
+		schema.SubscriptionType = t.Name
+	}
+
+	for _, t := range result.Schema.Types {
+		// Leave out the introspection system's own types
+		if strings.HasPrefix(t.Name, "__") {
+			continue
+		}
+
+		typ := model.GraphQLType{
+			Kind:        t.Kind,
+			Name:        t.Name,
+			Description: t.Description,
+			Fields:      convertFields(t.Fields),
+		}
+		if t.Kind == "INPUT_OBJECT" {
+			typ.Fields = convertFields(t.InputFields)
+		}
+		for _, v := range t.EnumValues {
+			typ.EnumValues = append(typ.EnumValues, v.Name)
+		}
+		for _, p := range t.PossibleTypes {
+			typ.PossibleTypes = append(typ.PossibleTypes, p.Name)
+		}
+		schema.Types = append(schema.Types, typ)
+	}
+
+	return schema, nil
+}
+
+func convertFields(fields []introspectedField) []model.GraphQLField {
+	var result []model.GraphQLField
+	for _, f := range fields {
+		field := model.GraphQLField{
+			Name:        f.Name,
+			Description: f.Description,
+			Type:        f.Type.String(),
+			Args:        convertFields(f.Args),
+			Deprecated:  f.IsDeprecated,
+		}
+		if f.DefaultValue != nil {
+			field.DefaultValue = *f.DefaultValue
+		}
+		result = append(result, field)
+	}
+	return result
+}
@@ -0,0 +1,97 @@
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+
+	"golang.org/x/net/http2"
+)
+
+// Protocol selects the HTTP version used to send requests
+type Protocol string
+
+const (
+	// ProtocolAuto uses HTTP/2 when a TLS server offers it and HTTP/1.1 otherwise
+	ProtocolAuto Protocol = ""
+
+	// ProtocolHTTP1 always uses HTTP/1.1, even if the server offers HTTP/2
+	ProtocolHTTP1 Protocol = "http1.1"
+
+	// ProtocolHTTP2 requires HTTP/2 negotiated over TLS
+	ProtocolHTTP2 Protocol = "http2"
+
+	// ProtocolH2C speaks HTTP/2 without negotiation: cleartext HTTP/2 (h2c)
+	// for http:// URLs, and HTTP/2 over TLS for https:// URLs
+	ProtocolH2C Protocol = "h2c"
+)
+
+// WithProtocol sets the HTTP version used to send requests
+func WithProtocol(p Protocol) Option {
+	return func(c *Client) {
+		c.client.Transport = newTransport(p)
+	}
+}
+
+// newTransport returns a round tripper speaking protocol p. Responses are
+// never decompressed by the transport; decodeBody handles every encoding.
+func newTransport(p Protocol) http.RoundTripper {
+	switch p {
+	case ProtocolHTTP1:
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		t.DisableCompression = true
+		t.ForceAttemptHTTP2 = false
+		// A non-nil, empty map disables the transport's built-in HTTP/2 support
+		t.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+		t.TLSClientConfig = &tls.Config{NextProtos: []string{"http/1.1"}}
+		return t
+	case ProtocolHTTP2:
+		return &schemeTransport{
+			https: &http2.Transport{DisableCompression: true},
+			http:  errTransport{fmt.Errorf("HTTP/2 over TLS needs an https URL; use h2c (prior knowledge) for cleartext HTTP/2")},
+		}
+	case ProtocolH2C:
+		return &schemeTransport{
+			https: &http2.Transport{DisableCompression: true},
+			http: &http2.Transport{
+				AllowHTTP:          true,
+				DisableCompression: true,
+				// Dial a plain TCP connection in place of TLS to speak h2c
+				DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+					var d net.Dialer
+					return d.DialContext(ctx, network, addr)
+				},
+			},
+		}
+	}
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.DisableCompression = true
+	return t
+}
+
+// schemeTransport picks a round tripper based on the request's URL scheme
+type schemeTransport struct {
+	http  http.RoundTripper
+	https http.RoundTripper
+}
+
+func (t *schemeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme == "https" {
+		return t.https.RoundTrip(req)
+	}
+	return t.http.RoundTrip(req)
+}
+
+// errTransport is a round tripper that fails every request with the same error
+type errTransport struct {
+	err error
+}
+
+func (t errTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	return nil, t.err
+}
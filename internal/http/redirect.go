@@ -0,0 +1,128 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"api/internal/model"
+)
+
+// DefaultMaxRedirects matches the limit of Go's default client
+const DefaultMaxRedirects = 10
+
+// RedirectAuth decides whether credentials are re-sent when following a redirect
+type RedirectAuth string
+
+const (
+	// RedirectAuthSameHost drops credentials when a redirect leaves the original
+	// host or its subdomains (the net/http default)
+	RedirectAuthSameHost RedirectAuth = "same-host"
+
+	// RedirectAuthAlways re-sends credentials to whichever host a redirect points to
+	RedirectAuthAlways RedirectAuth = "always"
+
+	// RedirectAuthNever drops credentials on every redirect, even to the same host
+	RedirectAuthNever RedirectAuth = "never"
+)
+
+// ParseRedirectAuth validates a redirect credential policy name
+func ParseRedirectAuth(s string) (RedirectAuth, error) {
+	switch auth := RedirectAuth(s); auth {
+	case RedirectAuthSameHost, RedirectAuthAlways, RedirectAuthNever:
+		return auth, nil
+	}
+	return "", fmt.Errorf("unknown redirect auth policy %q (use same-host, always or never)", s)
+}
+
+// redirectAuthHeaders are the credential headers the redirect policy applies to
+var redirectAuthHeaders = []string{"Authorization", "Www-Authenticate", "Cookie", "Cookie2"}
+
+// RedirectPolicy controls how redirects are followed
+type RedirectPolicy struct {
+	// Follow makes the client follow redirects; otherwise the 3xx response is returned
+	Follow bool
+
+	// MaxRedirects is the number of redirects followed before giving up
+	MaxRedirects int
+
+	// Auth decides whether credential headers are re-sent on redirects
+	Auth RedirectAuth
+}
+
+// DefaultRedirectPolicy follows up to DefaultMaxRedirects redirects, keeping
+// credentials only on the original host
+func DefaultRedirectPolicy() RedirectPolicy {
+	return RedirectPolicy{Follow: true, MaxRedirects: DefaultMaxRedirects, Auth: RedirectAuthSameHost}
+}
+
+// WithRedirectPolicy sets how the client follows redirects
+func WithRedirectPolicy(policy RedirectPolicy) Option {
+	return func(c *Client) {
+		c.redirects = policy
+	}
+}
+
+// redirectTrace applies the redirect policy to one request and records each hop
+type redirectTrace struct {
+	policy   RedirectPolicy
+	headers  model.Headers // headers given by the caller, without cookies added by a jar
+	hops     []model.Redirect
+	hopStart time.Time
+}
+
+// tracedClient returns a copy of the underlying client that follows redirects
+// according to the client's policy, recording hops in the returned trace
+func (c *Client) tracedClient(headers model.Headers) (*http.Client, *redirectTrace) {
+	trace := &redirectTrace{policy: c.redirects, headers: headers, hopStart: time.Now()}
+	client := *c.client
+	client.CheckRedirect = trace.checkRedirect
+	return &client, trace
+}
+
+// checkRedirect is called by net/http before following a redirect to req
+func (t *redirectTrace) checkRedirect(req *http.Request, via []*http.Request) error {
+	if !t.policy.Follow {
+		return http.ErrUseLastResponse
+	}
+
+	now := time.Now()
+	prev := via[len(via)-1]
+	if resp := req.Response; resp != nil {
+		t.hops = append(t.hops, model.Redirect{
+			Method:     prev.Method,
+			URL:        prev.URL.String(),
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			Location:   resp.Header.Get("Location"),
+			DurationMs: now.Sub(t.hopStart).Milliseconds(),
+		})
+	}
+	t.hopStart = now
+
+	if len(via) > t.policy.MaxRedirects {
+		return fmt.Errorf("stopped after %d redirects", t.policy.MaxRedirects)
+	}
+
+	// Each hop gets the same checks as the original URL, so a redirect can't
+	// reach a blocked host
+	if err := validateURL(req.URL.String()); err != nil {
+		return err
+	}
+
+	switch t.policy.Auth {
+	case RedirectAuthAlways:
+		// net/http has already dropped these if the host changed; put them back
+		for _, name := range redirectAuthHeaders {
+			if values := t.headers.Values(name); len(values) > 0 && req.Header.Get(name) == "" {
+				req.Header[http.CanonicalHeaderKey(name)] = values
+			}
+		}
+	case RedirectAuthNever:
+		for _, name := range redirectAuthHeaders {
+			req.Header.Del(name)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,310 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"api/internal/model"
+)
+
+// DefaultRetry is how long to wait before reconnecting to an event stream
+// until the server sets its own delay with a "retry:" field
+const DefaultRetry = 3 * time.Second
+
+// StreamOptions controls how Stream handles text/event-stream responses
+type StreamOptions struct {
+	// Context ends the stream cleanly when cancelled (e.g. on Ctrl-C)
+	Context context.Context
+
+	// Force parses the body as an event stream whatever its Content-Type
+	Force bool
+
+	// LastEventID is sent as the Last-Event-ID header to resume a stream
+	LastEventID string
+
+	// Reconnect re-opens the stream when the server closes it, sending the
+	// last event ID received. Requests with a body are never re-sent.
+	Reconnect bool
+
+	// MaxTranscript limits the bytes of events kept in the response body (0 for no limit)
+	MaxTranscript int
+
+	// OnOpen is called once the stream's response headers arrive
+	OnOpen func(resp *model.Response)
+
+	// OnEvent is called for each event as it arrives
+	OnEvent func(event model.Event)
+
+	// OnReconnect is called before waiting delay to reconnect. err is why the
+	// stream ended, or nil if the server closed it normally.
+	OnReconnect func(delay time.Duration, err error)
+}
+
+// IsEventStream reports whether a Content-Type is text/event-stream
+func IsEventStream(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == "text/event-stream"
+}
+
+// Stream executes a request and, if the response is an event stream, delivers
+// its events as they arrive instead of waiting for the body to end. The
+// returned response's body is a transcript of the events received. Other
+// responses are read and returned as by DoBody.
+func (c *Client) Stream(method, reqURL string, headers model.Headers, body *Body, opts StreamOptions) (*model.Response, error) {
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if body != nil {
+		if closer, ok := body.Reader.(io.Closer); ok {
+			defer closer.Close()
+		}
+	}
+
+	lastEventID := opts.LastEventID
+	retry := DefaultRetry
+	transcript := &eventTranscript{limit: opts.MaxTranscript}
+	start := time.Now()
+	var result *model.Response
+
+	for {
+		sendHeaders := headers
+		if lastEventID != "" {
+			sendHeaders = headers.Clone()
+			if sendHeaders == nil {
+				sendHeaders = make(model.Headers)
+			}
+			sendHeaders.Set("Last-Event-ID", lastEventID)
+		}
+
+		// Only the first connection carries the body; reconnects send none
+		reqBody := body
+		if result != nil {
+			reqBody = nil
+		}
+		req, err := newRequest(method, reqURL, sendHeaders, reqBody)
+		if err != nil {
+			return nil, err
+		}
+		c.setAcceptEncoding(req)
+
+		// Streams have no end, so the client timeout only bounds the wait for
+		// headers and, for ordinary responses, the rest of the exchange
+		req, timeout := withResponseTimeout(req.WithContext(ctx))
+
+		client, trace := c.tracedClient(headers)
+		client.Timeout = 0
+
+		resp, err := client.Do(req)
+		if err != nil {
+			timeout.release()
+			if timeout.timedOut() {
+				err = fmt.Errorf("timed out after %s waiting for a response", DefaultTimeout)
+			}
+			if result == nil {
+				return nil, err
+			}
+			// A failed reconnect is retried like a dropped stream
+			if ctx.Err() != nil || !waitToReconnect(ctx, opts, retry, err) {
+				break
+			}
+			continue
+		}
+
+		decoded, err := decodeBody(resp)
+		if err != nil {
+			resp.Body.Close()
+			timeout.release()
+			return nil, err
+		}
+
+		if !opts.Force && !IsEventStream(resp.Header.Get("Content-Type")) {
+			if result != nil {
+				// The server stopped serving a stream; don't keep reconnecting
+				decoded.Close()
+				resp.Body.Close()
+				timeout.release()
+				break
+			}
+			respBody, err := readLimitedBody(decoded)
+			decoded.Close()
+			resp.Body.Close()
+			timeout.release()
+			if err != nil {
+				if timeout.timedOut() {
+					err = fmt.Errorf("timed out after %s reading the response", DefaultTimeout)
+				}
+				return nil, err
+			}
+			ordinary := buildResponse(resp, respBody, time.Since(start))
+			ordinary.WireSize = decoded.WireSize()
+			ordinary.Redirects = trace.hops
+			return ordinary, nil
+		}
+		timeout.stop()
+
+		if result == nil {
+			result = buildResponse(resp, nil, time.Since(start))
+			result.Redirects = trace.hops
+			result.Streamed = true
+			if opts.OnOpen != nil {
+				opts.OnOpen(result)
+			}
+		}
+
+		err = readEvents(decoded, &lastEventID, &retry, func(event model.Event) {
+			transcript.add(event)
+			if opts.OnEvent != nil {
+				opts.OnEvent(event)
+			}
+		})
+		decoded.Close()
+		resp.Body.Close()
+		timeout.release()
+
+		if ctx.Err() != nil || !opts.Reconnect || body != nil || resp.StatusCode == http.StatusNoContent {
+			break
+		}
+		if !waitToReconnect(ctx, opts, retry, err) {
+			break
+		}
+	}
+
+	if result == nil {
+		return nil, ctx.Err()
+	}
+	result.Body = transcript.String()
+	result.DurationMs = time.Since(start).Milliseconds()
+	return result, nil
+}
+
+// waitToReconnect waits delay before the next connection attempt, returning
+// false if ctx is cancelled first
+func waitToReconnect(ctx context.Context, opts StreamOptions, delay time.Duration, err error) bool {
+	if opts.OnReconnect != nil {
+		opts.OnReconnect(delay, err)
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// readEvents parses a text/event-stream body, calling dispatch for each
+// complete event. "id:" and "retry:" fields update *lastEventID and *retry,
+// which carry over between connections. It returns nil when the stream ends normally.
+func readEvents(r io.Reader, lastEventID *string, retry *time.Duration, dispatch func(model.Event)) error {
+	reader := bufio.NewReader(r)
+
+	var eventType string
+	var data strings.Builder
+	hasData := false
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			// An event cut off by the end of the stream is discarded
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+
+		// A blank line dispatches the event built so far
+		if line == "" {
+			if hasData {
+				if eventType == "" {
+					eventType = "message"
+				}
+				dispatch(model.Event{
+					ID:   *lastEventID,
+					Type: eventType,
+					Data: strings.TrimSuffix(data.String(), "\n"),
+				})
+			}
+			eventType = ""
+			data.Reset()
+			hasData = false
+			continue
+		}
+
+		// Lines starting with a colon are comments (often keep-alives)
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+
+		switch field {
+		case "event":
+			eventType = value
+		case "data":
+			data.WriteString(value)
+			data.WriteString("\n")
+			hasData = true
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				*lastEventID = value
+			}
+		case "retry":
+			if ms, err := strconv.Atoi(value); err == nil && ms >= 0 {
+				*retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+}
+
+// eventTranscript records events in text/event-stream format up to a size limit
+type eventTranscript struct {
+	buf     strings.Builder
+	limit   int
+	dropped int
+}
+
+func (t *eventTranscript) add(event model.Event) {
+	if t.dropped > 0 {
+		t.dropped++
+		return
+	}
+
+	var b strings.Builder
+	if event.ID != "" {
+		fmt.Fprintf(&b, "id: %s\n", event.ID)
+	}
+	if event.Type != "message" {
+		fmt.Fprintf(&b, "event: %s\n", event.Type)
+	}
+	for _, line := range strings.Split(event.Data, "\n") {
+		fmt.Fprintf(&b, "data: %s\n", line)
+	}
+	b.WriteString("\n")
+
+	if t.limit > 0 && t.buf.Len()+b.Len() > t.limit {
+		t.dropped++
+		return
+	}
+	t.buf.WriteString(b.String())
+}
+
+// String returns the transcript, noting any events left out as an SSE comment
+func (t *eventTranscript) String() string {
+	if t.dropped == 0 {
+		return t.buf.String()
+	}
+	return t.buf.String() + fmt.Sprintf(": %d more events not recorded\n", t.dropped)
+}
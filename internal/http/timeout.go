@@ -0,0 +1,81 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// responseTimeout cancels a request that takes longer than DefaultTimeout to
+// connect or to answer. Unlike http.Client.Timeout it is paused while the body
+// is written, so slow uploads of large bodies are never cut off.
+type responseTimeout struct {
+	cancel  context.CancelFunc
+	mu      sync.Mutex
+	timer   *time.Timer
+	stopped bool
+	expired atomic.Bool
+}
+
+// withResponseTimeout returns req with a context bounded by a response
+// timeout. The timer runs until stop is called.
+func withResponseTimeout(req *http.Request) (*http.Request, *responseTimeout) {
+	ctx, cancel := context.WithCancel(req.Context())
+	t := &responseTimeout{cancel: cancel}
+	trace := &httptrace.ClientTrace{
+		// Each redirect hop writes a new request, pausing and restarting the timer
+		WroteHeaders: t.pause,
+		WroteRequest: func(httptrace.WroteRequestInfo) { t.start() },
+	}
+	t.start()
+	return req.WithContext(httptrace.WithClientTrace(ctx, trace)), t
+}
+
+// start (re)arms the timer
+func (t *responseTimeout) start() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.stopped {
+		return
+	}
+	if t.timer != nil {
+		t.timer.Stop()
+	}
+	t.timer = time.AfterFunc(DefaultTimeout, func() {
+		t.expired.Store(true)
+		t.cancel()
+	})
+}
+
+// pause stops the timer while the request body is sent
+func (t *responseTimeout) pause() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.timer != nil {
+		t.timer.Stop()
+	}
+}
+
+// stop disarms the timer for good, leaving the request running
+func (t *responseTimeout) stop() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stopped = true
+	if t.timer != nil {
+		t.timer.Stop()
+	}
+}
+
+// release stops the timer and ends the request's context
+func (t *responseTimeout) release() {
+	t.stop()
+	t.cancel()
+}
+
+// timedOut reports whether the timer cancelled the request
+func (t *responseTimeout) timedOut() bool {
+	return t.expired.Load()
+}
@@ -0,0 +1,154 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"api/internal/model"
+)
+
+// WebSocket is an open WebSocket connection. It records a transcript of the
+// messages sent and received, prefixed with "> " and "< " respectively.
+type WebSocket struct {
+	conn *websocket.Conn
+
+	// Handshake is the 101 Switching Protocols response that opened the connection
+	Handshake *model.Response
+
+	mu         sync.Mutex
+	transcript strings.Builder
+	limit      int
+	dropped    int
+}
+
+// WebSocketURL converts an http(s) URL to its ws(s) equivalent. ws and wss
+// URLs are returned unchanged.
+func WebSocketURL(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL: %w", err)
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "ws", "wss":
+	case "http":
+		u.Scheme = "ws"
+	case "https":
+		u.Scheme = "wss"
+	default:
+		return "", fmt.Errorf("unsupported URL scheme: %s (use ws, wss, http or https)", u.Scheme)
+	}
+	return u.String(), nil
+}
+
+// DialWebSocket opens a WebSocket connection. transcriptLimit caps the bytes
+// of transcript kept (0 for no limit).
+func (c *Client) DialWebSocket(rawURL string, headers model.Headers, transcriptLimit int) (*WebSocket, error) {
+	wsURL, err := WebSocketURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	// Apply the same URL checks as HTTP requests to the equivalent http(s) URL
+	httpURL := "http" + strings.TrimPrefix(wsURL, "ws")
+	if err := validateURL(httpURL); err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(wsURL, "ws://") {
+		fmt.Fprintln(os.Stderr, "WARNING: Using insecure WebSocket connection. Data will be transmitted unencrypted.")
+	}
+
+	dialer := websocket.Dialer{
+		Proxy:            http.ProxyFromEnvironment,
+		HandshakeTimeout: DefaultTimeout,
+		Jar:              c.client.Jar,
+	}
+
+	reqHeader := make(http.Header, len(headers))
+	for key, values := range headers {
+		reqHeader[http.CanonicalHeaderKey(key)] = append([]string(nil), values...)
+	}
+
+	start := time.Now()
+	conn, resp, err := dialer.Dial(wsURL, reqHeader)
+	if err != nil {
+		if errors.Is(err, websocket.ErrBadHandshake) && resp != nil {
+			return nil, fmt.Errorf("WebSocket handshake failed: %s", resp.Status)
+		}
+		return nil, err
+	}
+
+	return &WebSocket{
+		conn:      conn,
+		Handshake: buildResponse(resp, nil, time.Since(start)),
+		limit:     transcriptLimit,
+	}, nil
+}
+
+// SendText sends a text message
+func (ws *WebSocket) SendText(msg string) error {
+	if err := ws.conn.WriteMessage(websocket.TextMessage, []byte(msg)); err != nil {
+		return err
+	}
+	ws.record("> ", msg, false)
+	return nil
+}
+
+// Receive waits for the next data message. It returns io.EOF once the server
+// closes the connection normally.
+func (ws *WebSocket) Receive() (data string, binary bool, err error) {
+	msgType, msg, err := ws.conn.ReadMessage()
+	if err != nil {
+		if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway, websocket.CloseNoStatusReceived) {
+			return "", false, io.EOF
+		}
+		return "", false, err
+	}
+
+	binary = msgType == websocket.BinaryMessage
+	ws.record("< ", string(msg), binary)
+	return string(msg), binary, nil
+}
+
+// Close sends a close frame and closes the connection
+func (ws *WebSocket) Close() error {
+	closeMsg := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+	ws.conn.WriteControl(websocket.CloseMessage, closeMsg, time.Now().Add(time.Second))
+	return ws.conn.Close()
+}
+
+// Transcript returns the messages exchanged so far, one per line
+func (ws *WebSocket) Transcript() string {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	if ws.dropped == 0 {
+		return ws.transcript.String()
+	}
+	return ws.transcript.String() + fmt.Sprintf("(%d more messages not recorded)\n", ws.dropped)
+}
+
+// record appends a message to the transcript, summarizing binary frames
+func (ws *WebSocket) record(prefix, msg string, binary bool) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	if binary {
+		msg = fmt.Sprintf("(binary message, %d bytes)", len(msg))
+	}
+	line := prefix + strings.ReplaceAll(msg, "\n", "\n  ") + "\n"
+
+	if ws.dropped > 0 || (ws.limit > 0 && ws.transcript.Len()+len(line) > ws.limit) {
+		ws.dropped++
+		return
+	}
+	ws.transcript.WriteString(line)
+}
@@ -0,0 +1,59 @@
+package model
+
+import (
+	"encoding/json"
+)
+
+// GraphQLRequest is the JSON document POSTed to a GraphQL endpoint
+type GraphQLRequest struct {
+	Query         string          `json:"query"`
+	Variables     json.RawMessage `json:"variables,omitempty"`
+	OperationName string          `json:"operationName,omitempty"`
+}
+
+// GraphQLResult is the JSON document a GraphQL endpoint responds with
+type GraphQLResult struct {
+	Data   json.RawMessage `json:"data,omitempty"`
+	Errors []GraphQLError  `json:"errors,omitempty"`
+}
+
+// GraphQLError is a single entry of a GraphQL response's errors list
+type GraphQLError struct {
+	Message   string            `json:"message"`
+	Path      []interface{}     `json:"path,omitempty"` // field names and list indexes
+	Locations []GraphQLLocation `json:"locations,omitempty"`
+}
+
+// GraphQLLocation is a position in the query document
+type GraphQLLocation struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// GraphQLSchema is the part of an introspected schema shown to users
+type GraphQLSchema struct {
+	QueryType        string
+	MutationType     string
+	SubscriptionType string
+	Types            []GraphQLType
+}
+
+// GraphQLType is a named type in a schema
+type GraphQLType struct {
+	Kind          string // OBJECT, INTERFACE, UNION, ENUM, INPUT_OBJECT or SCALAR
+	Name          string
+	Description   string
+	Fields        []GraphQLField // fields of objects and interfaces, or input object fields
+	EnumValues    []string
+	PossibleTypes []string // members of unions and implementations of interfaces
+}
+
+// GraphQLField is a field of a type, or an argument of a field
+type GraphQLField struct {
+	Name         string
+	Description  string
+	Type         string // in GraphQL notation, e.g. "[User!]!"
+	Args         []GraphQLField
+	DefaultValue string
+	Deprecated   bool
+}
@@ -0,0 +1,16 @@
+package model
+
+// GRPCService is a gRPC service and the methods it offers
+type GRPCService struct {
+	Name    string
+	Methods []GRPCMethod
+}
+
+// GRPCMethod describes a gRPC method's messages and streaming
+type GRPCMethod struct {
+	Name            string
+	InputType       string
+	OutputType      string
+	ClientStreaming bool
+	ServerStreaming bool
+}
@@ -0,0 +1,118 @@
+package model
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// Headers maps header names to their values. Repeated headers (e.g. multiple
+// Set-Cookie or Link headers) keep every value in the order received. Names
+// keep the case they were given with, but lookups ignore case.
+type Headers map[string][]string
+
+// Get returns the first value of a header, or "" if it isn't set
+func (h Headers) Get(name string) string {
+	if values := h.Values(name); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// Values returns all values of a header
+func (h Headers) Values(name string) []string {
+	if key, ok := h.key(name); ok {
+		return h[key]
+	}
+	return nil
+}
+
+// Has reports whether a header is set
+func (h Headers) Has(name string) bool {
+	_, ok := h.key(name)
+	return ok
+}
+
+// Add appends a value to a header, keeping any existing values
+func (h Headers) Add(name, value string) {
+	if key, ok := h.key(name); ok {
+		h[key] = append(h[key], value)
+		return
+	}
+	h[name] = []string{value}
+}
+
+// Set replaces all values of a header with a single value
+func (h Headers) Set(name, value string) {
+	h.Del(name)
+	h[name] = []string{value}
+}
+
+// Del removes a header
+func (h Headers) Del(name string) {
+	for key := range h {
+		if strings.EqualFold(key, name) {
+			delete(h, key)
+		}
+	}
+}
+
+// Keys returns the header names in sorted order
+func (h Headers) Keys() []string {
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Clone returns a deep copy of the headers
+func (h Headers) Clone() Headers {
+	if h == nil {
+		return nil
+	}
+	clone := make(Headers, len(h))
+	for k, values := range h {
+		clone[k] = append([]string(nil), values...)
+	}
+	return clone
+}
+
+// key finds the stored name matching name, ignoring case
+func (h Headers) key(name string) (string, bool) {
+	if _, ok := h[name]; ok {
+		return name, true
+	}
+	for k := range h {
+		if strings.EqualFold(k, name) {
+			return k, true
+		}
+	}
+	return "", false
+}
+
+// UnmarshalJSON accepts both the current {"Name": ["v1", "v2"]} form and the
+// older single-value {"Name": "v"} form used before repeated headers were kept
+func (h *Headers) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	headers := make(Headers, len(raw))
+	for k, v := range raw {
+		var values []string
+		if err := json.Unmarshal(v, &values); err != nil {
+			var single string
+			if err := json.Unmarshal(v, &single); err != nil {
+				return err
+			}
+			values = []string{single}
+		}
+		headers[k] = values
+	}
+
+	*h = headers
+	return nil
+}
@@ -6,31 +6,89 @@ import (
 
 // Request represents an HTTP request
 type Request struct {
-	ID        string            `json:"id"`
-	Timestamp time.Time         `json:"timestamp"`
-	Method    string            `json:"method"`
-	URL       string            `json:"url"`
-	Headers   map[string]string `json:"headers"`
-	Body      string            `json:"body"`
-	Response  *Response         `json:"response,omitempty"`
+	ID        string    `json:"id"`
+	Timestamp time.Time `json:"timestamp"`
+	Method    string    `json:"method"`
+	URL       string    `json:"url"`
+	Headers   Headers   `json:"headers"`
+	Body      string    `json:"body"`
+	Response  *Response `json:"response,omitempty"`
+	Pinned    bool      `json:"pinned,omitempty"` // never pruned from history
+	Tags      []string  `json:"tags,omitempty"`
+	Note      string    `json:"note,omitempty"`
 }
 
 // Response represents an HTTP response
 type Response struct {
-	StatusCode int               `json:"status_code"`
-	Status     string            `json:"status"`
-	Headers    map[string]string `json:"headers"`
-	Body       string            `json:"body"`
-	DurationMs int64             `json:"duration_ms"`
+	StatusCode   int        `json:"status_code"`
+	Status       string     `json:"status"`
+	Protocol     string     `json:"protocol,omitempty"` // e.g. "HTTP/1.1" or "HTTP/2.0"
+	Headers      Headers    `json:"headers"`
+	Body         string     `json:"body"`                    // raw bytes after undoing any Content-Encoding; may be binary
+	BodyEncoding string     `json:"body_encoding,omitempty"` // "base64" when Body was encoded for JSON or YAML output
+	ContentType  string     `json:"content_type,omitempty"`
+	WireSize     int64      `json:"wire_size,omitempty"` // encoded size received when the body was compressed
+	DurationMs   int64      `json:"duration_ms"`
+	Download     *Download  `json:"download,omitempty"`
+	Redirects    []Redirect `json:"redirects,omitempty"` // hops followed before this response, in order
+	Streamed     bool       `json:"streamed,omitempty"`  // events were shown as they arrived; Body is their transcript
 }
 
+// Event is a single server-sent event from a text/event-stream response
+type Event struct {
+	ID   string `json:"id,omitempty"` // last event ID seen when the event was dispatched
+	Type string `json:"type"`         // "message" unless the server named the event
+	Data string `json:"data"`
+}
+
+// Redirect is one redirect response followed on the way to the final response
+type Redirect struct {
+	Method     string `json:"method"`
+	URL        string `json:"url"`
+	StatusCode int    `json:"status_code"`
+	Status     string `json:"status"`
+	Location   string `json:"location"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// Download describes a response body that was saved to a file instead of kept in memory
+type Download struct {
+	Path    string `json:"path"`
+	Size    int64  `json:"size"`
+	SHA256  string `json:"sha256"`
+	Resumed bool   `json:"resumed,omitempty"`
+}
+
+// Kinds of saved request
+const (
+	RequestKindHTTP    = "http"
+	RequestKindGraphQL = "graphql" // Body is a GraphQLRequest document
+)
+
 // SavedRequest represents a request saved in a collection (without response)
 type SavedRequest struct {
-	Name    string            `json:"name"`
-	Method  string            `json:"method"`
-	URL     string            `json:"url"`
-	Headers map[string]string `json:"headers"`
-	Body    string            `json:"body"`
+	Name    string       `json:"name"`
+	Kind    string       `json:"kind,omitempty"` // RequestKindHTTP if empty
+	Method  string       `json:"method"`
+	URL     string       `json:"url"`
+	Query   []QueryParam `json:"query,omitempty"` // added to the URL's own query when sent
+	Headers Headers      `json:"headers"`
+	Body    string       `json:"body"`
+}
+
+// RequestKind returns the kind of request, treating an unset kind as HTTP
+func (r SavedRequest) RequestKind() string {
+	if r.Kind == "" {
+		return RequestKindHTTP
+	}
+	return r.Kind
+}
+
+// QueryParam is a single URL query parameter. Values are stored unencoded and
+// may contain {{env:NAME}} or {{secret:name}} references.
+type QueryParam struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
 }
 
 // Collection represents a group of saved requests
@@ -44,11 +102,59 @@ type History struct {
 	Requests []Request `json:"requests"`
 }
 
+// HistoryFilter selects history entries; zero fields match every entry
+type HistoryFilter struct {
+	Tags   []string // entries must have every one of these tags
+	Note   string   // text the entry's note must contain, ignoring case
+	Pinned bool     // only pinned entries
+}
+
+// EndpointStats summarizes the history entries for one method on one endpoint
+type EndpointStats struct {
+	Host     string `json:"host"`
+	Endpoint string `json:"endpoint"` // URL path with IDs replaced by :id
+	Method   string `json:"method"`
+	Count    int    `json:"count"`
+	Errors   int    `json:"errors"` // responses with a 4xx or 5xx status
+	P50Ms    int64  `json:"p50_ms"`
+	P95Ms    int64  `json:"p95_ms"`
+	P99Ms    int64  `json:"p99_ms"`
+	AvgSize  int64  `json:"avg_size"` // decoded response body size in bytes
+	MaxSize  int64  `json:"max_size"`
+}
+
+// ErrorRate returns the fraction of responses that were errors
+func (s EndpointStats) ErrorRate() float64 {
+	if s.Count == 0 {
+		return 0
+	}
+	return float64(s.Errors) / float64(s.Count)
+}
+
 // Collections represents all collections storage
 type Collections struct {
 	Collections map[string]Collection `json:"collections"`
 }
 
+// Cookie is a cookie remembered by a session
+type Cookie struct {
+	Name     string    `json:"name"`
+	Value    string    `json:"value"`
+	Domain   string    `json:"domain"`
+	Path     string    `json:"path"`
+	Expires  time.Time `json:"expires,omitempty"` // zero for cookies that last until the session is cleared
+	Secure   bool      `json:"secure,omitempty"`
+	HttpOnly bool      `json:"http_only,omitempty"`
+	HostOnly bool      `json:"host_only,omitempty"` // sent only to Domain itself, not its subdomains
+}
+
+// Session is a named cookie jar carried between requests
+type Session struct {
+	Name      string    `json:"name"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Cookies   []Cookie  `json:"cookies"`
+}
+
 // Aliases represents all URL aliases storage
 type Aliases struct {
 	Aliases map[string]string `json:"aliases"` // name -> base URL
@@ -0,0 +1,244 @@
+package storage
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	secretsFile = "secrets.enc"
+
+	// Version of the on-disk vault format
+	vaultVersion = 1
+
+	// scrypt parameters for deriving the vault key from the passphrase
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	vaultKeySize = 32 // AES-256
+	vaultSaltLen = 16
+)
+
+// ErrWrongPassphrase is returned when the vault cannot be decrypted with the given passphrase
+var ErrWrongPassphrase = errors.New("incorrect passphrase or corrupted secrets file")
+
+// vaultFile is the on-disk representation of the encrypted secrets vault
+type vaultFile struct {
+	Version    int    `json:"version"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// SecretVault stores named secrets encrypted with a passphrase-derived key (AES-GCM)
+type SecretVault struct {
+	path    string
+	key     []byte
+	salt    []byte
+	secrets map[string]string
+}
+
+// SecretVaultExists reports whether a secrets vault has been created
+func SecretVaultExists() (bool, error) {
+	path, err := secretVaultPath()
+	if err != nil {
+		return false, err
+	}
+
+	_, err = os.Stat(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// OpenSecretVault decrypts the secrets vault with the given passphrase.
+// If no vault exists yet, an empty one is returned and created on first Save.
+func OpenSecretVault(passphrase string) (*SecretVault, error) {
+	if passphrase == "" {
+		return nil, fmt.Errorf("passphrase must not be empty")
+	}
+
+	path, err := secretVaultPath()
+	if err != nil {
+		return nil, err
+	}
+
+	v := &SecretVault{path: path, secrets: make(map[string]string)}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		// New vault - generate a fresh salt for key derivation
+		v.salt = make([]byte, vaultSaltLen)
+		if _, err := rand.Read(v.salt); err != nil {
+			return nil, fmt.Errorf("failed to generate salt: %w", err)
+		}
+		v.key, err = deriveVaultKey(passphrase, v.salt)
+		if err != nil {
+			return nil, err
+		}
+		return v, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var vf vaultFile
+	if err := json.Unmarshal(data, &vf); err != nil {
+		return nil, fmt.Errorf("failed to parse secrets file: %w", err)
+	}
+	if vf.Version != vaultVersion {
+		return nil, fmt.Errorf("unsupported secrets file version: %d", vf.Version)
+	}
+
+	v.salt = vf.Salt
+	v.key, err = deriveVaultKey(passphrase, vf.Salt)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := newVaultCipher(v.key)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := gcm.Open(nil, vf.Nonce, vf.Ciphertext, nil)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+
+	if err := json.Unmarshal(plaintext, &v.secrets); err != nil {
+		return nil, fmt.Errorf("failed to parse secrets: %w", err)
+	}
+	if v.secrets == nil {
+		v.secrets = make(map[string]string)
+	}
+
+	return v, nil
+}
+
+// Get returns the value of a secret
+func (v *SecretVault) Get(name string) (string, bool) {
+	value, exists := v.secrets[name]
+	return value, exists
+}
+
+// Set stores a secret value (call Save to persist)
+func (v *SecretVault) Set(name, value string) {
+	v.secrets[name] = value
+}
+
+// Delete removes a secret (call Save to persist)
+func (v *SecretVault) Delete(name string) bool {
+	if _, exists := v.secrets[name]; !exists {
+		return false
+	}
+	delete(v.secrets, name)
+	return true
+}
+
+// Names returns the sorted names of all stored secrets
+func (v *SecretVault) Names() []string {
+	names := make([]string, 0, len(v.secrets))
+	for name := range v.secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Save encrypts the secrets with a fresh nonce and writes them to disk
+func (v *SecretVault) Save() error {
+	plaintext, err := json.Marshal(v.secrets)
+	if err != nil {
+		return err
+	}
+
+	gcm, err := newVaultCipher(v.key)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	data, err := json.MarshalIndent(vaultFile{
+		Version:    vaultVersion,
+		Salt:       v.salt,
+		Nonce:      nonce,
+		Ciphertext: gcm.Seal(nil, nonce, plaintext, nil),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Write to a temp file and rename so a failed write never corrupts the vault.
+	// The mode is set explicitly in case a stale temp file was left behind.
+	tmpPath := v.path + ".tmp"
+	f, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, secureFileMode)
+	if err != nil {
+		return err
+	}
+	if err := f.Chmod(secureFileMode); err != nil {
+		f.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return os.Rename(tmpPath, v.path)
+}
+
+// secretVaultPath returns the path to the encrypted secrets file
+func secretVaultPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	dataDir := filepath.Join(homeDir, ".apicli")
+	if err := os.MkdirAll(dataDir, secureDirMode); err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dataDir, secretsFile), nil
+}
+
+// deriveVaultKey derives the AES key from the passphrase using scrypt
+func deriveVaultKey(passphrase string, salt []byte) ([]byte, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, vaultKeySize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	return key, nil
+}
+
+// newVaultCipher creates the AES-GCM AEAD used to encrypt the vault
+func newVaultCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
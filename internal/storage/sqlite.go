@@ -6,25 +6,28 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"api/internal/model"
 
 	_ "modernc.org/sqlite"
 )
 
-// parseJSONHeaders safely parses JSON headers, returning an empty map on error
-func parseJSONHeaders(jsonStr string) (map[string]string, error) {
+// parseJSONHeaders safely parses JSON headers, returning an empty map on error.
+// Both the multi-value and the older single-value forms are accepted.
+func parseJSONHeaders(jsonStr string) (model.Headers, error) {
 	if jsonStr == "" {
-		return make(map[string]string), nil
+		return make(model.Headers), nil
 	}
 
-	var headers map[string]string
+	var headers model.Headers
 	if err := json.Unmarshal([]byte(jsonStr), &headers); err != nil {
-		return make(map[string]string), fmt.Errorf("failed to parse headers JSON: %w", err)
+		return make(model.Headers), fmt.Errorf("failed to parse headers JSON: %w", err)
 	}
 
 	if headers == nil {
-		headers = make(map[string]string)
+		headers = make(model.Headers)
 	}
 	return headers, nil
 }
@@ -109,6 +112,11 @@ func NewStorage() (*SQLiteStorage, error) {
 		return nil, err
 	}
 
+	if err := s.migrateSchema(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
 	// Attempt migration from JSON files if database is empty
 	if err := s.migrateFromJSON(); err != nil {
 		// Log but don't fail - migration errors shouldn't prevent startup
@@ -172,19 +180,262 @@ func (s *SQLiteStorage) initSchema() error {
 	return err
 }
 
+// migrations upgrade the schema created by initSchema. Each entry moves the
+// database to the next version, tracked in SQLite's user_version pragma.
+var migrations = []func(tx *sql.Tx) error{
+	// 1: response bodies saved to files record the path and checksum instead
+	execMigration(`ALTER TABLE history ADD COLUMN response_download TEXT`),
+	// 2: response bodies are stored as BLOBs alongside their content type
+	execMigration(`ALTER TABLE history ADD COLUMN response_content_type TEXT`),
+	// 3: headers keep every value of repeated headers ({"Name": ["v1", "v2"]})
+	migrateMultiValueHeaders,
+	// 4: named sessions persist the cookies set by the servers they talk to
+	execMigration(`
+	CREATE TABLE sessions (
+		name TEXT PRIMARY KEY,
+		updated_at DATETIME NOT NULL
+	);
+	CREATE TABLE session_cookies (
+		session TEXT NOT NULL,
+		name TEXT NOT NULL,
+		value TEXT NOT NULL,
+		domain TEXT NOT NULL,
+		path TEXT NOT NULL,
+		expires DATETIME,
+		secure INTEGER NOT NULL DEFAULT 0,
+		http_only INTEGER NOT NULL DEFAULT 0,
+		host_only INTEGER NOT NULL DEFAULT 0,
+		PRIMARY KEY (session, domain, path, name),
+		FOREIGN KEY (session) REFERENCES sessions(name) ON DELETE CASCADE
+	);`),
+	// 5: responses record the redirects followed to reach them
+	execMigration(`ALTER TABLE history ADD COLUMN response_redirects TEXT`),
+	// 6: responses record the negotiated protocol (HTTP/1.1, HTTP/2.0)
+	execMigration(`ALTER TABLE history ADD COLUMN response_protocol TEXT`),
+	// 7: saved requests and aliases carry structured query parameters
+	execMigration(`
+	ALTER TABLE saved_requests ADD COLUMN query TEXT;
+	ALTER TABLE aliases ADD COLUMN query TEXT;`),
+	// 8: saved requests record their kind (plain HTTP or GraphQL)
+	execMigration(`ALTER TABLE saved_requests ADD COLUMN kind TEXT NOT NULL DEFAULT 'http'`),
+	// 9: compressed responses record their size on the wire
+	execMigration(`ALTER TABLE history ADD COLUMN response_wire_size INTEGER`),
+	// 10: history entries can be pinned, tagged and annotated with a note
+	execMigration(`
+	ALTER TABLE history ADD COLUMN pinned INTEGER NOT NULL DEFAULT 0;
+	CREATE TABLE history_tags (
+		history_id TEXT NOT NULL,
+		tag TEXT NOT NULL,
+		PRIMARY KEY (history_id, tag),
+		FOREIGN KEY (history_id) REFERENCES history(id) ON DELETE CASCADE
+	);
+	CREATE INDEX idx_history_tags_tag ON history_tags(tag);
+	CREATE TABLE history_notes (
+		history_id TEXT PRIMARY KEY,
+		note TEXT NOT NULL,
+		updated_at DATETIME NOT NULL,
+		FOREIGN KEY (history_id) REFERENCES history(id) ON DELETE CASCADE
+	);`),
+}
+
+// parseJSONQuery parses stored query parameters, returning nil for an empty column
+func parseJSONQuery(jsonStr sql.NullString) []model.QueryParam {
+	if !jsonStr.Valid || jsonStr.String == "" {
+		return nil
+	}
+	var query []model.QueryParam
+	json.Unmarshal([]byte(jsonStr.String), &query)
+	return query
+}
+
+// queryJSON encodes query parameters for storage, or NULL if there are none
+func queryJSON(query []model.QueryParam) sql.NullString {
+	if len(query) == 0 {
+		return sql.NullString{}
+	}
+	encoded, _ := json.Marshal(query)
+	return sql.NullString{String: string(encoded), Valid: true}
+}
+
+// execMigration returns a migration that runs a single SQL statement
+func execMigration(stmt string) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		_, err := tx.Exec(stmt)
+		return err
+	}
+}
+
+// migrateMultiValueHeaders rewrites {"Name": "v"} header columns to {"Name": ["v"]}
+func migrateMultiValueHeaders(tx *sql.Tx) error {
+	columns := []struct{ table, key, column string }{
+		{"history", "id", "headers"},
+		{"history", "id", "response_headers"},
+		{"saved_requests", "id", "headers"},
+	}
+
+	for _, c := range columns {
+		// Table and column names come from the fixed list above
+		rows, err := tx.Query(fmt.Sprintf("SELECT %s, %s FROM %s WHERE %s IS NOT NULL", c.key, c.column, c.table, c.column))
+		if err != nil {
+			return err
+		}
+
+		updates := make(map[interface{}]string)
+		for rows.Next() {
+			var key interface{}
+			var headersJSON string
+			if err := rows.Scan(&key, &headersJSON); err != nil {
+				rows.Close()
+				return err
+			}
+			headers, err := parseJSONHeaders(headersJSON)
+			if err != nil {
+				continue // leave unparseable rows as they are
+			}
+			converted, _ := json.Marshal(headers)
+			if string(converted) != headersJSON {
+				updates[key] = string(converted)
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for key, headersJSON := range updates {
+			if _, err := tx.Exec(fmt.Sprintf("UPDATE %s SET %s = ? WHERE %s = ?", c.table, c.column, c.key), headersJSON, key); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// migrateSchema applies any migrations the database hasn't seen yet
+func (s *SQLiteStorage) migrateSchema() error {
+	var version int
+	if err := s.db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return err
+	}
+
+	for i := version; i < len(migrations); i++ {
+		tx, err := s.db.Begin()
+		if err != nil {
+			return err
+		}
+		if err := migrations[i](tx); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d failed: %w", i+1, err)
+		}
+		// PRAGMA doesn't support placeholders; the version is an integer we control
+		if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", i+1)); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // =============================================================================
 // History Operations
 // =============================================================================
 
-// LoadHistory loads the request history from the database
-func (s *SQLiteStorage) LoadHistory() (*model.History, error) {
-	rows, err := s.db.Query(`
-		SELECT id, timestamp, method, url, headers, body,
+// historyColumns lists the history columns in the order scanHistoryRow expects
+const historyColumns = `id, timestamp, method, url, headers, body,
 		       response_status_code, response_status, response_headers,
-		       response_body, response_duration_ms
-		FROM history
-		ORDER BY timestamp DESC
-		LIMIT 100`)
+		       response_body, response_duration_ms, response_download,
+		       response_content_type, response_redirects, response_protocol,
+		       response_wire_size, pinned`
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanHistoryRow scans a history row selected with historyColumns
+func scanHistoryRow(row rowScanner) (*model.Request, error) {
+	var req model.Request
+	var headersJSON string
+	var respStatusCode, respDurationMs, respWireSize sql.NullInt64
+	var respStatus, respHeaders, respDownload, respContentType, respRedirects, respProtocol sql.NullString
+	var respBody []byte
+
+	err := row.Scan(
+		&req.ID, &req.Timestamp, &req.Method, &req.URL,
+		&headersJSON, &req.Body,
+		&respStatusCode, &respStatus, &respHeaders,
+		&respBody, &respDurationMs, &respDownload,
+		&respContentType, &respRedirects, &respProtocol,
+		&respWireSize, &req.Pinned,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse headers JSON (errors are logged but don't fail the operation)
+	req.Headers, _ = parseJSONHeaders(headersJSON)
+
+	// Build response if present
+	if respStatusCode.Valid {
+		req.Response = &model.Response{
+			StatusCode:  int(respStatusCode.Int64),
+			Status:      respStatus.String,
+			Protocol:    respProtocol.String,
+			Body:        string(respBody),
+			ContentType: respContentType.String,
+			WireSize:    respWireSize.Int64,
+			DurationMs:  respDurationMs.Int64,
+		}
+		if respHeaders.Valid {
+			req.Response.Headers, _ = parseJSONHeaders(respHeaders.String)
+		} else {
+			req.Response.Headers = make(model.Headers)
+		}
+		if respDownload.Valid && respDownload.String != "" {
+			var download model.Download
+			if json.Unmarshal([]byte(respDownload.String), &download) == nil {
+				req.Response.Download = &download
+			}
+		}
+		if respRedirects.Valid && respRedirects.String != "" {
+			json.Unmarshal([]byte(respRedirects.String), &req.Response.Redirects)
+		}
+	}
+
+	return &req, nil
+}
+
+// LoadHistory loads the request history from the database, newest first
+func (s *SQLiteStorage) LoadHistory() (*model.History, error) {
+	return s.SearchHistory(model.HistoryFilter{})
+}
+
+// SearchHistory loads the history entries matching filter, newest first
+func (s *SQLiteStorage) SearchHistory(filter model.HistoryFilter) (*model.History, error) {
+	var where []string
+	var args []interface{}
+	if filter.Pinned {
+		where = append(where, "pinned = 1")
+	}
+	for _, tag := range filter.Tags {
+		where = append(where, "id IN (SELECT history_id FROM history_tags WHERE tag = ?)")
+		args = append(args, tag)
+	}
+	if filter.Note != "" {
+		where = append(where, `id IN (SELECT history_id FROM history_notes WHERE note LIKE ? ESCAPE '\')`)
+		args = append(args, "%"+likeEscaper.Replace(filter.Note)+"%")
+	}
+
+	query := `SELECT ` + historyColumns + ` FROM history`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	rows, err := s.db.Query(query+" ORDER BY timestamp DESC", args...)
 	if err != nil {
 		return nil, err
 	}
@@ -193,45 +444,25 @@ func (s *SQLiteStorage) LoadHistory() (*model.History, error) {
 	history := &model.History{Requests: []model.Request{}}
 
 	for rows.Next() {
-		var req model.Request
-		var headersJSON string
-		var respStatusCode, respDurationMs sql.NullInt64
-		var respStatus, respHeaders, respBody sql.NullString
-
-		err := rows.Scan(
-			&req.ID, &req.Timestamp, &req.Method, &req.URL,
-			&headersJSON, &req.Body,
-			&respStatusCode, &respStatus, &respHeaders,
-			&respBody, &respDurationMs,
-		)
+		req, err := scanHistoryRow(rows)
 		if err != nil {
 			return nil, err
 		}
-
-		// Parse headers JSON (errors are logged but don't fail the operation)
-		req.Headers, _ = parseJSONHeaders(headersJSON)
-
-		// Build response if present
-		if respStatusCode.Valid {
-			req.Response = &model.Response{
-				StatusCode: int(respStatusCode.Int64),
-				Status:     respStatus.String,
-				Body:       respBody.String,
-				DurationMs: respDurationMs.Int64,
-			}
-			if respHeaders.Valid {
-				req.Response.Headers, _ = parseJSONHeaders(respHeaders.String)
-			} else {
-				req.Response.Headers = make(map[string]string)
-			}
-		}
-
-		history.Requests = append(history.Requests, req)
+		history.Requests = append(history.Requests, *req)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
-	return history, rows.Err()
+	if err := s.loadAnnotations(history.Requests); err != nil {
+		return nil, err
+	}
+	return history, nil
 }
 
+// likeEscaper escapes the wildcards of a LIKE pattern
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
 // SaveHistory replaces all history with the provided data
 func (s *SQLiteStorage) SaveHistory(history *model.History) error {
 	tx, err := s.db.Begin()
@@ -241,7 +472,7 @@ func (s *SQLiteStorage) SaveHistory(history *model.History) error {
 	defer tx.Rollback()
 
 	// Clear existing history
-	if _, err := tx.Exec("DELETE FROM history"); err != nil {
+	if _, err := tx.Exec("DELETE FROM history_tags; DELETE FROM history_notes; DELETE FROM history"); err != nil {
 		return err
 	}
 
@@ -268,12 +499,15 @@ func (s *SQLiteStorage) AddToHistory(req model.Request) error {
 		return err
 	}
 
-	// Enforce 100-request limit by deleting oldest entries
+	// Enforce 100-request limit by deleting the oldest unpinned entries,
+	// along with their tags and notes
 	_, err = tx.Exec(`
 		DELETE FROM history
-		WHERE id NOT IN (
-			SELECT id FROM history ORDER BY timestamp DESC LIMIT 100
-		)`)
+		WHERE pinned = 0 AND id NOT IN (
+			SELECT id FROM history WHERE pinned = 0 ORDER BY timestamp DESC LIMIT 100
+		);
+		DELETE FROM history_tags WHERE history_id NOT IN (SELECT id FROM history);
+		DELETE FROM history_notes WHERE history_id NOT IN (SELECT id FROM history);`)
 	if err != nil {
 		return err
 	}
@@ -285,56 +519,70 @@ func (s *SQLiteStorage) AddToHistory(req model.Request) error {
 func (s *SQLiteStorage) insertHistoryRequest(tx *sql.Tx, req model.Request) error {
 	headersJSON, _ := json.Marshal(req.Headers)
 
-	var respStatusCode, respDurationMs sql.NullInt64
-	var respStatus, respHeaders, respBody sql.NullString
+	var respStatusCode, respDurationMs, respWireSize sql.NullInt64
+	var respStatus, respHeaders, respDownload, respContentType, respRedirects, respProtocol sql.NullString
+	var respBody []byte // bound as a BLOB so binary bodies survive intact
 
 	if req.Response != nil {
 		respStatusCode = sql.NullInt64{Int64: int64(req.Response.StatusCode), Valid: true}
 		respStatus = sql.NullString{String: req.Response.Status, Valid: true}
+		respProtocol = sql.NullString{String: req.Response.Protocol, Valid: req.Response.Protocol != ""}
 		respHeadersJSON, _ := json.Marshal(req.Response.Headers)
 		respHeaders = sql.NullString{String: string(respHeadersJSON), Valid: true}
-		respBody = sql.NullString{String: req.Response.Body, Valid: true}
+		respBody = []byte(req.Response.Body)
+		respContentType = sql.NullString{String: req.Response.ContentType, Valid: true}
 		respDurationMs = sql.NullInt64{Int64: req.Response.DurationMs, Valid: true}
+		respWireSize = sql.NullInt64{Int64: req.Response.WireSize, Valid: req.Response.WireSize > 0}
+		if req.Response.Download != nil {
+			respDownloadJSON, _ := json.Marshal(req.Response.Download)
+			respDownload = sql.NullString{String: string(respDownloadJSON), Valid: true}
+		}
+		if len(req.Response.Redirects) > 0 {
+			respRedirectsJSON, _ := json.Marshal(req.Response.Redirects)
+			respRedirects = sql.NullString{String: string(respRedirectsJSON), Valid: true}
+		}
 	}
 
 	_, err := tx.Exec(`
-		INSERT OR REPLACE INTO history (
-			id, timestamp, method, url, headers, body,
-			response_status_code, response_status, response_headers,
-			response_body, response_duration_ms
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		INSERT OR REPLACE INTO history (`+historyColumns+`
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		req.ID, req.Timestamp, req.Method, req.URL, string(headersJSON), req.Body,
-		respStatusCode, respStatus, respHeaders, respBody, respDurationMs,
+		respStatusCode, respStatus, respHeaders, respBody, respDurationMs, respDownload,
+		respContentType, respRedirects, respProtocol, respWireSize, req.Pinned,
 	)
+	if err != nil {
+		return err
+	}
+
+	for _, tag := range req.Tags {
+		if _, err := tx.Exec("INSERT OR IGNORE INTO history_tags (history_id, tag) VALUES (?, ?)", req.ID, tag); err != nil {
+			return err
+		}
+	}
+	if req.Note != "" {
+		_, err = tx.Exec("INSERT OR REPLACE INTO history_notes (history_id, note, updated_at) VALUES (?, ?, ?)",
+			req.ID, req.Note, time.Now())
+	}
 	return err
 }
 
-// ClearHistory clears all history
+// ClearHistory clears all history, including pinned entries, tags and notes
 func (s *SQLiteStorage) ClearHistory() error {
-	_, err := s.db.Exec("DELETE FROM history")
+	_, err := s.db.Exec(`
+		DELETE FROM history_tags;
+		DELETE FROM history_notes;
+		DELETE FROM history;`)
 	return err
 }
 
 // GetHistoryRequest gets a specific request by ID
 func (s *SQLiteStorage) GetHistoryRequest(id string) (*model.Request, error) {
 	row := s.db.QueryRow(`
-		SELECT id, timestamp, method, url, headers, body,
-		       response_status_code, response_status, response_headers,
-		       response_body, response_duration_ms
+		SELECT `+historyColumns+`
 		FROM history
 		WHERE id = ?`, id)
 
-	var req model.Request
-	var headersJSON string
-	var respStatusCode, respDurationMs sql.NullInt64
-	var respStatus, respHeaders, respBody sql.NullString
-
-	err := row.Scan(
-		&req.ID, &req.Timestamp, &req.Method, &req.URL,
-		&headersJSON, &req.Body,
-		&respStatusCode, &respStatus, &respHeaders,
-		&respBody, &respDurationMs,
-	)
+	req, err := scanHistoryRow(row)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -342,25 +590,112 @@ func (s *SQLiteStorage) GetHistoryRequest(id string) (*model.Request, error) {
 		return nil, err
 	}
 
-	// Parse headers JSON (errors are logged but don't fail the operation)
-	req.Headers, _ = parseJSONHeaders(headersJSON)
+	requests := []model.Request{*req}
+	if err := s.loadAnnotations(requests); err != nil {
+		return nil, err
+	}
+	return &requests[0], nil
+}
 
-	// Build response if present
-	if respStatusCode.Valid {
-		req.Response = &model.Response{
-			StatusCode: int(respStatusCode.Int64),
-			Status:     respStatus.String,
-			Body:       respBody.String,
-			DurationMs: respDurationMs.Int64,
+// loadAnnotations fills in the tags and notes of history entries
+func (s *SQLiteStorage) loadAnnotations(requests []model.Request) error {
+	if len(requests) == 0 {
+		return nil
+	}
+	byID := make(map[string]*model.Request, len(requests))
+	for i := range requests {
+		byID[requests[i].ID] = &requests[i]
+	}
+
+	rows, err := s.db.Query("SELECT history_id, tag FROM history_tags ORDER BY tag")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id, tag string
+		if err := rows.Scan(&id, &tag); err != nil {
+			return err
 		}
-		if respHeaders.Valid {
-			req.Response.Headers, _ = parseJSONHeaders(respHeaders.String)
-		} else {
-			req.Response.Headers = make(map[string]string)
+		if req := byID[id]; req != nil {
+			req.Tags = append(req.Tags, tag)
 		}
 	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
 
-	return &req, nil
+	noteRows, err := s.db.Query("SELECT history_id, note FROM history_notes")
+	if err != nil {
+		return err
+	}
+	defer noteRows.Close()
+	for noteRows.Next() {
+		var id, note string
+		if err := noteRows.Scan(&id, &note); err != nil {
+			return err
+		}
+		if req := byID[id]; req != nil {
+			req.Note = note
+		}
+	}
+	return noteRows.Err()
+}
+
+// AddHistoryTags tags a history entry, ignoring tags it already has
+func (s *SQLiteStorage) AddHistoryTags(id string, tags ...string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, tag := range tags {
+		if _, err := tx.Exec("INSERT OR IGNORE INTO history_tags (history_id, tag) VALUES (?, ?)", id, tag); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// RemoveHistoryTags removes tags from a history entry
+func (s *SQLiteStorage) RemoveHistoryTags(id string, tags ...string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, tag := range tags {
+		if _, err := tx.Exec("DELETE FROM history_tags WHERE history_id = ? AND tag = ?", id, tag); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// SetHistoryNote sets the note on a history entry; an empty note removes it
+func (s *SQLiteStorage) SetHistoryNote(id, note string) error {
+	if note == "" {
+		_, err := s.db.Exec("DELETE FROM history_notes WHERE history_id = ?", id)
+		return err
+	}
+	_, err := s.db.Exec("INSERT OR REPLACE INTO history_notes (history_id, note, updated_at) VALUES (?, ?, ?)",
+		id, note, time.Now())
+	return err
+}
+
+// SetHistoryPinned pins or unpins a history entry. Pinned entries are kept
+// when the oldest entries are pruned.
+func (s *SQLiteStorage) SetHistoryPinned(id string, pinned bool) error {
+	result, err := s.db.Exec("UPDATE history SET pinned = ? WHERE id = ?", pinned, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("request not found: %s", id)
+	}
+	return nil
 }
 
 // =============================================================================
@@ -403,7 +738,7 @@ func (s *SQLiteStorage) LoadCollections() (*model.Collections, error) {
 		}
 
 		reqRows, err := s.db.Query(`
-			SELECT name, method, url, headers, body
+			SELECT name, kind, method, url, query, headers, body
 			FROM saved_requests
 			WHERE collection_id = ?
 			ORDER BY position`, col.id)
@@ -414,10 +749,12 @@ func (s *SQLiteStorage) LoadCollections() (*model.Collections, error) {
 		for reqRows.Next() {
 			var req model.SavedRequest
 			var headersJSON string
-			if err := reqRows.Scan(&req.Name, &req.Method, &req.URL, &headersJSON, &req.Body); err != nil {
+			var queryColumn sql.NullString
+			if err := reqRows.Scan(&req.Name, &req.Kind, &req.Method, &req.URL, &queryColumn, &headersJSON, &req.Body); err != nil {
 				reqRows.Close()
 				return nil, err
 			}
+			req.Query = parseJSONQuery(queryColumn)
 			// Parse headers JSON (errors are logged but don't fail the operation)
 			req.Headers, _ = parseJSONHeaders(headersJSON)
 			collection.Requests = append(collection.Requests, req)
@@ -458,9 +795,9 @@ func (s *SQLiteStorage) SaveCollections(collections *model.Collections) error {
 		for i, req := range col.Requests {
 			headersJSON, _ := json.Marshal(req.Headers)
 			_, err := tx.Exec(`
-				INSERT INTO saved_requests (collection_id, name, method, url, headers, body, position)
-				VALUES (?, ?, ?, ?, ?, ?, ?)`,
-				colID, req.Name, req.Method, req.URL, string(headersJSON), req.Body, i)
+				INSERT INTO saved_requests (collection_id, name, kind, method, url, query, headers, body, position)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+				colID, req.Name, req.RequestKind(), req.Method, req.URL, queryJSON(req.Query), string(headersJSON), req.Body, i)
 			if err != nil {
 				return err
 			}
@@ -499,7 +836,7 @@ func (s *SQLiteStorage) GetCollection(name string) (*model.Collection, error) {
 	}
 
 	rows, err := s.db.Query(`
-		SELECT name, method, url, headers, body
+		SELECT name, kind, method, url, query, headers, body
 		FROM saved_requests
 		WHERE collection_id = ?
 		ORDER BY position`, colID)
@@ -511,9 +848,11 @@ func (s *SQLiteStorage) GetCollection(name string) (*model.Collection, error) {
 	for rows.Next() {
 		var req model.SavedRequest
 		var headersJSON string
-		if err := rows.Scan(&req.Name, &req.Method, &req.URL, &headersJSON, &req.Body); err != nil {
+		var queryColumn sql.NullString
+		if err := rows.Scan(&req.Name, &req.Kind, &req.Method, &req.URL, &queryColumn, &headersJSON, &req.Body); err != nil {
 			return nil, err
 		}
+		req.Query = parseJSONQuery(queryColumn)
 		// Parse headers JSON (errors are logged but don't fail the operation)
 		req.Headers, _ = parseJSONHeaders(headersJSON)
 		collection.Requests = append(collection.Requests, req)
@@ -554,9 +893,9 @@ func (s *SQLiteStorage) AddToCollection(collectionName string, req model.SavedRe
 	// Insert request
 	headersJSON, _ := json.Marshal(req.Headers)
 	_, err = tx.Exec(`
-		INSERT INTO saved_requests (collection_id, name, method, url, headers, body, position)
-		VALUES (?, ?, ?, ?, ?, ?, ?)`,
-		colID, req.Name, req.Method, req.URL, string(headersJSON), req.Body, nextPos)
+		INSERT INTO saved_requests (collection_id, name, kind, method, url, query, headers, body, position)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		colID, req.Name, req.RequestKind(), req.Method, req.URL, queryJSON(req.Query), string(headersJSON), req.Body, nextPos)
 	if err != nil {
 		return err
 	}
@@ -640,6 +979,160 @@ func (s *SQLiteStorage) GetAlias(name string) (string, bool, error) {
 	return url, true, nil
 }
 
+// GetAliasQuery gets the default query parameters sent with requests using an alias
+func (s *SQLiteStorage) GetAliasQuery(name string) ([]model.QueryParam, error) {
+	var query sql.NullString
+	err := s.db.QueryRow("SELECT query FROM aliases WHERE name = ?", name).Scan(&query)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseJSONQuery(query), nil
+}
+
+// SetAliasQuery replaces the default query parameters of an existing alias
+func (s *SQLiteStorage) SetAliasQuery(name string, query []model.QueryParam) error {
+	_, err := s.db.Exec("UPDATE aliases SET query = ? WHERE name = ?", queryJSON(query), name)
+	return err
+}
+
+// =============================================================================
+// Session Operations
+// =============================================================================
+
+// LoadSessions loads all sessions and their cookies, ordered by name
+func (s *SQLiteStorage) LoadSessions() ([]model.Session, error) {
+	rows, err := s.db.Query("SELECT name FROM sessions ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sessions := []model.Session{}
+	for _, name := range names {
+		session, err := s.GetSession(name)
+		if err != nil {
+			return nil, err
+		}
+		if session != nil {
+			sessions = append(sessions, *session)
+		}
+	}
+
+	return sessions, nil
+}
+
+// GetSession gets a session and its cookies by name
+func (s *SQLiteStorage) GetSession(name string) (*model.Session, error) {
+	session := &model.Session{Name: name, Cookies: []model.Cookie{}}
+	err := s.db.QueryRow("SELECT updated_at FROM sessions WHERE name = ?", name).Scan(&session.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(`
+		SELECT name, value, domain, path, expires, secure, http_only, host_only
+		FROM session_cookies
+		WHERE session = ?
+		ORDER BY domain, path, name`, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cookie model.Cookie
+		var expires sql.NullTime
+		if err := rows.Scan(&cookie.Name, &cookie.Value, &cookie.Domain, &cookie.Path,
+			&expires, &cookie.Secure, &cookie.HttpOnly, &cookie.HostOnly); err != nil {
+			return nil, err
+		}
+		if expires.Valid {
+			cookie.Expires = expires.Time
+		}
+		session.Cookies = append(session.Cookies, cookie)
+	}
+
+	return session, rows.Err()
+}
+
+// SaveSession creates or replaces a session with the provided cookies
+func (s *SQLiteStorage) SaveSession(session model.Session) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO sessions (name, updated_at) VALUES (?, ?)
+		ON CONFLICT(name) DO UPDATE SET updated_at = excluded.updated_at`,
+		session.Name, session.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM session_cookies WHERE session = ?", session.Name); err != nil {
+		return err
+	}
+
+	for _, cookie := range session.Cookies {
+		var expires sql.NullTime
+		if !cookie.Expires.IsZero() {
+			expires = sql.NullTime{Time: cookie.Expires, Valid: true}
+		}
+		_, err := tx.Exec(`
+			INSERT OR REPLACE INTO session_cookies
+				(session, name, value, domain, path, expires, secure, http_only, host_only)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			session.Name, cookie.Name, cookie.Value, cookie.Domain, cookie.Path,
+			expires, cookie.Secure, cookie.HttpOnly, cookie.HostOnly)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// DeleteSession deletes a session and its cookies, reporting whether it existed
+func (s *SQLiteStorage) DeleteSession(name string) (bool, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM session_cookies WHERE session = ?", name); err != nil {
+		return false, err
+	}
+	result, err := tx.Exec("DELETE FROM sessions WHERE name = ?", name)
+	if err != nil {
+		return false, err
+	}
+	n, _ := result.RowsAffected()
+
+	return n > 0, tx.Commit()
+}
+
 // =============================================================================
 // Migration from JSON
 // =============================================================================
@@ -0,0 +1,174 @@
+package storage
+
+import (
+	"database/sql/driver"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"api/internal/model"
+
+	"modernc.org/sqlite"
+)
+
+// SQL functions used to group history by endpoint and select it by time
+func init() {
+	sqlite.MustRegisterDeterministicScalarFunction("url_host", 1, urlFunction(urlHost))
+	sqlite.MustRegisterDeterministicScalarFunction("url_endpoint", 1, urlFunction(endpointPattern))
+	sqlite.MustRegisterDeterministicScalarFunction("unix_time", 1, unixTime)
+}
+
+// storedTimeFormats are the layouts the driver writes and reads timestamps in,
+// besides the time.String form it uses by default
+var storedTimeFormats = []string{
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02T15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+}
+
+// unixTime is a SQL function returning a stored timestamp as Unix seconds, or
+// NULL if it can't be parsed. Timestamps are stored as text in the zone they
+// were written in, so comparing the text itself breaks across zones.
+func unixTime(_ *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+	var s string
+	switch v := args[0].(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	case time.Time:
+		return v.Unix(), nil
+	default:
+		return nil, nil
+	}
+
+	// time.String form: "2006-01-02 15:04:05.999999999 -0700 MST m=+0.017"
+	if i := strings.Index(s, " m="); i > 0 {
+		s = s[:i]
+	}
+	if t, err := time.Parse("2006-01-02 15:04:05.999999999 -0700 MST", s); err == nil {
+		return t.Unix(), nil
+	}
+	for _, layout := range storedTimeFormats {
+		if t, err := time.Parse(layout, strings.TrimSuffix(s, "Z")); err == nil {
+			return t.Unix(), nil
+		}
+	}
+	return nil, nil
+}
+
+// urlFunction adapts fn to a one-argument SQL function on a URL string
+func urlFunction(fn func(string) string) func(*sqlite.FunctionContext, []driver.Value) (driver.Value, error) {
+	return func(_ *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+		switch v := args[0].(type) {
+		case string:
+			return fn(v), nil
+		case []byte:
+			return fn(string(v)), nil
+		}
+		return "", nil
+	}
+}
+
+// urlHost returns the host (and port, if any) of a history URL
+func urlHost(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(parsed.Host)
+}
+
+// idSegmentPattern matches path segments that may identify a single
+// resource: numbers, UUIDs, hashes and other tokens containing a digit
+var idSegmentPattern = regexp.MustCompile(`^[A-Za-z0-9_-]*[0-9][A-Za-z0-9_-]*$`)
+
+// endpointPattern returns a URL's path with resource IDs replaced by :id, so
+// /users/42 and /users/7 count as the same endpoint
+func endpointPattern(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	segments := strings.Split(parsed.EscapedPath(), "/")
+	for i, segment := range segments {
+		if isIDSegment(segment) {
+			segments[i] = ":id"
+		}
+	}
+	path := strings.Join(segments, "/")
+	if path == "" {
+		path = "/"
+	}
+	return path
+}
+
+// isIDSegment reports whether a path segment looks like a resource ID rather
+// than a fixed name. Short alphanumeric names such as v2 or oauth2 are kept.
+func isIDSegment(segment string) bool {
+	if segment == "" || !idSegmentPattern.MatchString(segment) {
+		return false
+	}
+	if strings.Trim(segment, "0123456789") == "" {
+		return true
+	}
+	return len(segment) >= 8
+}
+
+// historyStatsQuery aggregates history by host, endpoint and method.
+// Percentiles use the nearest-rank method over each group's durations.
+const historyStatsQuery = `
+	WITH entries AS (
+		SELECT url_host(url) AS host, url_endpoint(url) AS endpoint, method,
+		       response_status_code AS status, response_duration_ms AS duration,
+		       COALESCE(json_extract(response_download, '$.size'),
+		                length(CAST(response_body AS BLOB)), 0) AS size
+		FROM history
+		WHERE response_status_code IS NOT NULL AND COALESCE(unix_time(timestamp), 0) >= ?
+	),
+	ranked AS (
+		SELECT *,
+		       ROW_NUMBER() OVER (PARTITION BY host, endpoint, method ORDER BY duration) AS rank,
+		       COUNT(*) OVER (PARTITION BY host, endpoint, method) AS n
+		FROM entries
+	)
+	SELECT host, endpoint, method, n,
+	       SUM(status >= 400),
+	       MIN(CASE WHEN rank >= 0.50 * n THEN duration END),
+	       MIN(CASE WHEN rank >= 0.95 * n THEN duration END),
+	       MIN(CASE WHEN rank >= 0.99 * n THEN duration END),
+	       CAST(AVG(size) AS INTEGER), MAX(size)
+	FROM ranked
+	GROUP BY host, endpoint, method
+	ORDER BY n DESC, host, endpoint, method`
+
+// HistoryStats returns per-endpoint counts, error rates, latency percentiles
+// and response sizes for the history entries recorded since the given time
+// (all of them if since is zero)
+func (s *SQLiteStorage) HistoryStats(since time.Time) ([]model.EndpointStats, error) {
+	var from int64
+	if !since.IsZero() {
+		from = since.Unix()
+	}
+
+	rows, err := s.db.Query(historyStatsQuery, from)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := []model.EndpointStats{}
+	for rows.Next() {
+		var st model.EndpointStats
+		if err := rows.Scan(
+			&st.Host, &st.Endpoint, &st.Method, &st.Count, &st.Errors,
+			&st.P50Ms, &st.P95Ms, &st.P99Ms, &st.AvgSize, &st.MaxSize,
+		); err != nil {
+			return nil, err
+		}
+		stats = append(stats, st)
+	}
+	return stats, rows.Err()
+}